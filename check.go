@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+	"github.com/meetsoni15/noisemap/internal/report"
+)
+
+// Exit codes for `noisemap check`.
+const (
+	exitOK        = 0 // gate passed
+	exitViolation = 1 // gate failed
+	exitError     = 2 // bad usage or the scan itself failed
+)
+
+// runCheck implements `noisemap check`: a CI quality gate. It returns the
+// process exit code.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	var sf scanFlags
+	sf.register(fs)
+	failOn := fs.String("fail-on", "critical", "fail when any file reaches `band` (low, medium, high, critical or none)")
+	maxHigh := fs.Int("max-high", -1, "fail when more than `N` files are High or Critical (-1 disables)")
+	maxComplexity := fs.Int("max-complexity", 0, "fail when any function's complexity exceeds `N` (0 disables)")
+	maxCognitive := fs.Int("max-cognitive", 0, "fail when any function's cognitive complexity exceeds `N` (0 disables)")
+
+	positional, err := parseArgs(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitError
+	}
+
+	gate := report.Gate{
+		MaxHigh:           *maxHigh,
+		MaxFuncComplexity: *maxComplexity,
+		MaxFuncCognitive:  *maxCognitive,
+	}
+	if *failOn != "none" {
+		band, err := analyze.ParseRiskBand(*failOn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitError
+		}
+		gate.FailOn = &band
+	}
+
+	root, err := resolveRoot(positional)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	scores, err := scanScores(root, &sf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	violations := gate.Evaluate(scores)
+	report.WriteSummary(os.Stdout, scores, violations)
+	if len(violations) > 0 {
+		return exitViolation
+	}
+	return exitOK
+}
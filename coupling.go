@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meetsoni15/noisemap/internal/report"
+)
+
+// runCoupling implements `noisemap coupling`: lists the files that most
+// often change in the same commits.
+func runCoupling(args []string) error {
+	fs := flag.NewFlagSet("coupling", flag.ContinueOnError)
+	var sf scanFlags
+	sf.register(fs)
+	format := fs.String("format", "text", "output format (text or json)")
+	limit := fs.Int("limit", 20, "show at most `N` pairs (0 for all)")
+	minShared := fs.Int("min-shared", 0, "only pair files sharing at least `N` commits (default 3)")
+	maxFiles := fs.Int("max-files", 0, "skip commits touching more than `N` files (default 50)")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported format %q (supported: text, json)", *format)
+	}
+
+	root, err := resolveRoot(positional)
+	if err != nil {
+		return err
+	}
+	opts, err := sf.options(root)
+	if err != nil {
+		return err
+	}
+	if *minShared > 0 {
+		opts.Coupling.MinShared = *minShared
+	}
+	if *maxFiles > 0 {
+		opts.Coupling.MaxFiles = *maxFiles
+	}
+
+	scores, err := scanWithOptions(root, opts, sf.quiet)
+	if err != nil {
+		return err
+	}
+
+	c := report.NewCoupling(root, scores, opts.Coupling, *limit)
+	if *format == "json" {
+		return c.WriteJSON(os.Stdout)
+	}
+	c.WriteText(os.Stdout)
+	return nil
+}
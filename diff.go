@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/meetsoni15/noisemap/internal/report"
+)
+
+// runDiff implements `noisemap diff`: compares a baseline report against
+// a fresh scan of a directory or against a second saved report.
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var sf scanFlags
+	sf.register(fs)
+	format := fs.String("format", "text", "output format (text or json)")
+	minDelta := fs.Float64("min-delta", 1, "ignore risk score moves smaller than `points` unless the band changed")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unsupported format %q (supported: text, json)", *format)
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf("usage: noisemap diff <baseline.json> [directory | report.json]")
+	}
+
+	baseline, err := report.Load(positional[0])
+	if err != nil {
+		return err
+	}
+
+	var current *report.Report
+	if len(positional) == 2 {
+		if info, err := os.Stat(positional[1]); err == nil && !info.IsDir() {
+			current, err = report.Load(positional[1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if current == nil {
+		root, err := resolveRoot(positional[1:])
+		if err != nil {
+			return err
+		}
+		current, err = scanReport(root, &sf)
+		if err != nil {
+			return err
+		}
+	}
+
+	d := report.Compare(baseline, current, *minDelta)
+	if *format == "json" {
+		return d.WriteJSON(os.Stdout)
+	}
+	d.WriteText(os.Stdout)
+	return nil
+}
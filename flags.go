@@ -0,0 +1,258 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/meetsoni15/noisemap/internal/analyze"
+	"github.com/meetsoni15/noisemap/internal/config"
+)
+
+// scanFlags are the flags shared by every command that runs a scan.
+type scanFlags struct {
+	fs *flag.FlagSet
+
+	jobs     int
+	quiet    bool
+	noCache  bool
+	cacheDir string
+
+	configPath       string
+	noConfig         bool
+	noGitignore      bool
+	complexityMetric string
+	churnMetric      string
+	scorer           string
+	complexityWeight float64
+	churnWeight      float64
+	bands            string
+	since            string
+	until            string
+	granularity      string
+	halfLife         string
+	keepMerges       bool
+	ignoreAuthors    patternList
+	ignoreRevsFile   string
+	maxCommitFiles   int
+	include          globList
+	exclude          globList
+}
+
+// globList is a repeatable flag of doublestar patterns.
+type globList []string
+
+func (g *globList) String() string { return strings.Join(*g, " ") }
+
+func (g *globList) Set(v string) error {
+	if !doublestar.ValidatePattern(v) {
+		return fmt.Errorf("invalid glob %q", v)
+	}
+	*g = append(*g, v)
+	return nil
+}
+
+// patternList is a repeatable flag of regular expressions.
+type patternList []string
+
+func (p *patternList) String() string { return strings.Join(*p, " ") }
+
+func (p *patternList) Set(v string) error {
+	if _, err := analyze.CompileAuthorPatterns([]string{v}); err != nil {
+		return err
+	}
+	*p = append(*p, v)
+	return nil
+}
+
+// register adds the scan flags to fs.
+func (f *scanFlags) register(fs *flag.FlagSet) {
+	f.fs = fs
+	fs.IntVar(&f.jobs, "jobs", 0, "analyze `N` files in parallel (default: number of CPUs)")
+	fs.IntVar(&f.jobs, "j", 0, "shorthand for --jobs")
+	fs.BoolVar(&f.quiet, "quiet", false, "do not print scan progress to stderr")
+	fs.BoolVar(&f.quiet, "q", false, "shorthand for --quiet")
+	fs.BoolVar(&f.noCache, "no-cache", false, "analyze every file from scratch and do not update the cache")
+	fs.StringVar(&f.cacheDir, "cache-dir", "", "keep the analysis cache in `dir` (default: user cache dir)")
+
+	fs.StringVar(&f.configPath, "config", "", "read settings from `file` instead of the nearest .noisemap.yml")
+	fs.BoolVar(&f.noConfig, "no-config", false, "ignore .noisemap.yml files")
+	fs.BoolVar(&f.noGitignore, "no-gitignore", false, "scan files ignored by .gitignore and .git/info/exclude")
+	fs.StringVar(&f.complexityMetric, "complexity-metric", "cyclomatic", "complexity `metric` that feeds the risk score: cyclomatic or cognitive")
+	fs.StringVar(&f.churnMetric, "churn-metric", "commits", "churn `metric` that feeds the risk score: commits, lines, added, deleted, net, relative, decayed or fixes")
+	fs.StringVar(&f.scorer, "scorer", analyze.DefaultScorer, "how complexity and churn combine into the risk score: linear, percentile, log or hotspot")
+	fs.Float64Var(&f.complexityWeight, "complexity-weight", analyze.DefaultWeights.Complexity, "weight of complexity in the risk score")
+	fs.Float64Var(&f.churnWeight, "churn-weight", analyze.DefaultWeights.Churn, "weight of churn in the risk score")
+	fs.StringVar(&f.bands, "bands", "", "risk scores where Medium,High,Critical start, e.g. `30,60,80`")
+	fs.StringVar(&f.since, "since", "", "only count churn after `date` (YYYY-MM-DD) or git revision")
+	fs.StringVar(&f.until, "until", "", "only count churn up to `date` (YYYY-MM-DD) or git revision")
+	fs.StringVar(&f.granularity, "granularity", "month", "width of each churn activity bucket: week, month or quarter")
+	fs.StringVar(&f.halfLife, "half-life", "90d", "age at which a commit counts half in decayed churn, e.g. `90d`, 2w, 6m or 1y")
+	fs.BoolVar(&f.keepMerges, "keep-merges", false, "count merge commits in churn")
+	fs.Var(&f.ignoreAuthors, "ignore-author", "skip commits whose author \"Name <email>\" matches `regexp` (repeatable; default: bots)")
+	fs.StringVar(&f.ignoreRevsFile, "ignore-revs-file", "", "skip the commits listed in `file` (default: .git-blame-ignore-revs)")
+	fs.IntVar(&f.maxCommitFiles, "max-commit-files", 0, "skip commits touching more than `N` files (0 for no limit)")
+	fs.Var(&f.include, "include", "only scan files matching `glob` (repeatable)")
+	fs.Var(&f.exclude, "exclude", "skip files matching `glob` (repeatable)")
+}
+
+// options builds the analysis options for root: defaults, then the
+// config file, then any flags given explicitly on the command line.
+func (f *scanFlags) options(root string) (analyze.Options, error) {
+	opts := analyze.Options{Jobs: f.jobs}
+	switch {
+	case f.noCache:
+	case f.cacheDir != "":
+		opts.CacheDir = f.cacheDir
+	default:
+		if dir, err := os.UserCacheDir(); err == nil {
+			opts.CacheDir = filepath.Join(dir, "noisemap")
+		}
+	}
+
+	path := f.configPath
+	if path == "" && !f.noConfig {
+		found, err := config.Find(root)
+		if err != nil {
+			return opts, err
+		}
+		path = found
+	}
+	if path != "" {
+		cfg, err := config.Load(path)
+		if err != nil {
+			return opts, err
+		}
+		cfg.Apply(&opts)
+	}
+
+	set := make(map[string]bool)
+	f.fs.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+
+	if set["complexity-metric"] {
+		m, err := analyze.ParseComplexityMetric(f.complexityMetric)
+		if err != nil {
+			return opts, err
+		}
+		opts.ComplexityMetric = m
+	}
+	if set["churn-metric"] {
+		m, err := analyze.ParseChurnMetric(f.churnMetric)
+		if err != nil {
+			return opts, err
+		}
+		opts.ChurnMetric = m
+	}
+
+	if set["scorer"] {
+		s, err := analyze.LookupScorer(f.scorer)
+		if err != nil {
+			return opts, err
+		}
+		opts.Scorer = s
+	}
+
+	if set["complexity-weight"] || set["churn-weight"] {
+		w := opts.Weights
+		if w == (analyze.Weights{}) {
+			w = analyze.DefaultWeights
+		}
+		if set["complexity-weight"] {
+			w.Complexity = f.complexityWeight
+		}
+		if set["churn-weight"] {
+			w.Churn = f.churnWeight
+		}
+		if err := w.Validate(); err != nil {
+			return opts, err
+		}
+		opts.Weights = w
+	}
+
+	// Globs on the command line replace those from the config file.
+	if set["include"] {
+		opts.Include = f.include
+	}
+	if set["exclude"] {
+		opts.Exclude = f.exclude
+	}
+
+	if set["no-gitignore"] {
+		opts.NoGitignore = f.noGitignore
+	}
+
+	if set["since"] {
+		opts.History.Since = f.since
+	}
+	if set["until"] {
+		opts.History.Until = f.until
+	}
+	if set["granularity"] {
+		g, err := analyze.ParseGranularity(f.granularity)
+		if err != nil {
+			return opts, err
+		}
+		opts.History.Granularity = g
+	}
+	if set["half-life"] {
+		d, err := analyze.ParseHalfLife(f.halfLife)
+		if err != nil {
+			return opts, err
+		}
+		opts.History.HalfLife = d
+	}
+
+	if set["keep-merges"] {
+		opts.Filters.KeepMerges = f.keepMerges
+	}
+	// Author patterns on the command line replace the defaults and those
+	// from the config file.
+	if set["ignore-author"] {
+		patterns, err := analyze.CompileAuthorPatterns(f.ignoreAuthors)
+		if err != nil {
+			return opts, err
+		}
+		opts.Filters.IgnoreAuthors = patterns
+	}
+	if set["ignore-revs-file"] {
+		opts.Filters.IgnoreRevsFile = f.ignoreRevsFile
+	}
+	if set["max-commit-files"] {
+		if f.maxCommitFiles < 0 {
+			return opts, fmt.Errorf("--max-commit-files must not be negative, got %d", f.maxCommitFiles)
+		}
+		opts.Filters.MaxFiles = f.maxCommitFiles
+	}
+
+	if set["bands"] {
+		t, err := parseBands(f.bands)
+		if err != nil {
+			return opts, err
+		}
+		opts.Thresholds = t
+	}
+
+	return opts, nil
+}
+
+// parseBands parses "medium,high,critical" cutoffs.
+func parseBands(s string) (analyze.Thresholds, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return analyze.Thresholds{}, fmt.Errorf("--bands wants three comma-separated numbers, got %q", s)
+	}
+	var vals [3]float64
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return analyze.Thresholds{}, fmt.Errorf("--bands: %w", err)
+		}
+		vals[i] = v
+	}
+	t := analyze.Thresholds{Medium: vals[0], High: vals[1], Critical: vals[2]}
+	return t, t.Validate()
+}
@@ -0,0 +1,247 @@
+package analyze
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Analyzer computes the complexity metrics of source files. Implementations
+// must be safe for concurrent use: a scan calls Analyze from several
+// workers at once.
+type Analyzer interface {
+	// Name identifies the analyzer in configuration, e.g. "go" or
+	// "generic". It is also part of the cache key, so settings that change
+	// an analyzer's output must show in its name.
+	Name() string
+	// Analyze returns the cyclomatic and cognitive complexity of the file
+	// at fi.Path. Unreadable or unparsable files still yield a result.
+	Analyze(fi FileInfo) ComplexityResult
+}
+
+// Qualifier is implemented by analyzers whose function identities depend
+// on where a file lives as well as on its content, such as Go import
+// paths. Qualify runs after every analysis, cached or not, so location
+// never ends up in the content-keyed cache.
+type Qualifier interface {
+	Qualify(fi FileInfo, res *ComplexityResult)
+}
+
+// analyzeFile runs a on fi through cache and qualifies the result.
+func analyzeFile(cache *Cache, a Analyzer, fi FileInfo) ComplexityResult {
+	res := cache.complexity(fi, recovering{a})
+	if q, ok := a.(Qualifier); ok {
+		// Copy so that cached entries stay location-free.
+		res.Functions = append([]FuncComplexity(nil), res.Functions...)
+		q.Qualify(fi, &res)
+	}
+	return res
+}
+
+// recovering falls back to the generic analyzer for a file that makes the
+// wrapped analyzer panic, so that one file cannot end a scan. The result
+// records the failure in Fallback and is cached under the wrapped
+// analyzer's name.
+type recovering struct{ Analyzer }
+
+func (r recovering) Analyze(fi FileInfo) (res ComplexityResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			res = analyzeGeneric(fi.Path)
+			res.Fallback = fmt.Sprintf("the %s analyzer failed: %v", r.Name(), err)
+		}
+	}()
+	return r.Analyzer.Analyze(fi)
+}
+
+// Language describes a language that noisemap recognizes.
+type Language struct {
+	// Name is the language name shown in reports, e.g. "Python".
+	Name string
+	// Extensions are the lower-case file extensions, with the dot.
+	Extensions []string
+	// Interpreters are the shebang interpreter names that identify
+	// extensionless scripts, without version suffixes: "python" matches
+	// both "#!/usr/bin/python3" and "#!/usr/bin/env python3.12".
+	Interpreters []string
+	// Analyzer is the name of the default analyzer. Empty or unknown
+	// names fall back to GenericAnalyzer.
+	Analyzer string
+}
+
+// GenericAnalyzer is the name of the line-based fallback analyzer used for
+// languages without a dedicated one.
+const GenericAnalyzer = "generic"
+
+// funcAnalyzer adapts an analysis function to the Analyzer interface.
+type funcAnalyzer struct {
+	name string
+	fn   func(path string) ComplexityResult
+}
+
+func (a funcAnalyzer) Name() string                         { return a.name }
+func (a funcAnalyzer) Analyze(fi FileInfo) ComplexityResult { return a.fn(fi.Path) }
+
+var registry = struct {
+	sync.RWMutex
+	analyzers map[string]Analyzer
+	languages map[string]Language
+}{
+	analyzers: map[string]Analyzer{
+		GenericAnalyzer: funcAnalyzer{GenericAnalyzer, analyzeGeneric},
+		"go":            GoAnalyzer{},
+		"python":        funcAnalyzer{"python", analyzePython},
+		"javascript":    funcAnalyzer{"javascript", analyzeJS},
+	},
+	languages: languageMap(builtinLanguages),
+}
+
+var builtinLanguages = []Language{
+	{Name: "Go", Extensions: []string{".go"}, Analyzer: "go"},
+	{Name: "JavaScript", Extensions: []string{".js", ".jsx", ".mjs", ".cjs"},
+		Interpreters: []string{"node", "nodejs"}, Analyzer: "javascript"},
+	{Name: "TypeScript", Extensions: []string{".ts", ".tsx", ".mts", ".cts"},
+		Interpreters: []string{"ts-node", "tsx", "deno", "bun"}, Analyzer: "javascript"},
+	{Name: "Python", Extensions: []string{".py"}, Interpreters: []string{"python"}, Analyzer: "python"},
+	{Name: "Java", Extensions: []string{".java"}},
+	{Name: "Rust", Extensions: []string{".rs"}},
+	{Name: "C", Extensions: []string{".c"}},
+	{Name: "C++", Extensions: []string{".cpp"}},
+	{Name: "Ruby", Extensions: []string{".rb"}, Interpreters: []string{"ruby"}},
+	{Name: "PHP", Extensions: []string{".php"}, Interpreters: []string{"php"}},
+}
+
+func languageMap(langs []Language) map[string]Language {
+	m := make(map[string]Language, len(langs))
+	for _, l := range langs {
+		m[l.Name] = l
+	}
+	return m
+}
+
+// SupportedExtensions maps file extensions to language names. It is the
+// default for Options.Extensions and grows with RegisterLanguage.
+var SupportedExtensions = func() map[string]string {
+	m := make(map[string]string)
+	for _, l := range builtinLanguages {
+		for _, ext := range l.Extensions {
+			m[ext] = l.Name
+		}
+	}
+	return m
+}()
+
+// RegisterAnalyzer makes a available under a.Name(), replacing any
+// analyzer of the same name. It must be called before scanning starts.
+func RegisterAnalyzer(a Analyzer) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.analyzers[a.Name()] = a
+}
+
+// RegisterLanguage adds or replaces a language, and maps its extensions
+// in SupportedExtensions. It must be called before scanning starts.
+func RegisterLanguage(l Language) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.languages[l.Name] = l
+	for _, ext := range l.Extensions {
+		SupportedExtensions[strings.ToLower(ext)] = l.Name
+	}
+}
+
+// LookupAnalyzer returns the analyzer registered under name.
+func LookupAnalyzer(name string) (Analyzer, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	if a, ok := registry.analyzers[name]; ok {
+		return a, nil
+	}
+	return nil, fmt.Errorf("unknown analyzer %q (want one of %s)", name, strings.Join(analyzerNames(), ", "))
+}
+
+// LookupLanguage returns the name of the registered language called name,
+// ignoring case.
+func LookupLanguage(name string) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for n := range registry.languages {
+		if strings.EqualFold(n, name) {
+			return n, true
+		}
+	}
+	return "", false
+}
+
+// analyzerNames lists the registered analyzers. The caller holds the lock.
+func analyzerNames() []string {
+	names := make([]string, 0, len(registry.analyzers))
+	for name := range registry.analyzers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AnalyzerFor returns the default analyzer for a language, falling back to
+// the generic analyzer.
+func AnalyzerFor(language string) Analyzer {
+	registry.RLock()
+	defer registry.RUnlock()
+	if a, ok := registry.analyzers[registry.languages[language].Analyzer]; ok {
+		return a
+	}
+	return registry.analyzers[GenericAnalyzer]
+}
+
+// LanguageForShebang returns the language whose interpreter runs a script
+// starting with line, e.g. "#!/usr/bin/env python3".
+func LanguageForShebang(line string) (string, bool) {
+	if !strings.HasPrefix(line, "#!") {
+		return "", false
+	}
+	fields := strings.Fields(line[2:])
+	if len(fields) == 0 {
+		return "", false
+	}
+	interp := path.Base(fields[0])
+	if interp == "env" {
+		// Skip env's own options, as in "#!/usr/bin/env -S deno run".
+		interp = ""
+		for _, f := range fields[1:] {
+			if !strings.HasPrefix(f, "-") {
+				interp = path.Base(f)
+				break
+			}
+		}
+	}
+	interp = strings.TrimRight(interp, "0123456789.")
+
+	registry.RLock()
+	defer registry.RUnlock()
+	for _, l := range registry.languages {
+		for _, name := range l.Interpreters {
+			if name == interp {
+				return l.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AnalyzeComplexity returns the complexity of a file using the default
+// analyzer for its language.
+func AnalyzeComplexity(fi FileInfo) ComplexityResult {
+	return analyzeFile(nil, AnalyzerFor(fi.Language), fi)
+}
+
+// analyzer returns the analyzer for a language under opts: a per-language
+// override, else the language's default.
+func (o Options) analyzer(language string) Analyzer {
+	if a, ok := o.Analyzers[language]; ok {
+		return a
+	}
+	return AnalyzerFor(language)
+}
@@ -0,0 +1,27 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type panicAnalyzer struct{}
+
+func (panicAnalyzer) Name() string                      { return "panic" }
+func (panicAnalyzer) Analyze(FileInfo) ComplexityResult { panic("boom") }
+
+func TestAnalyzeFileRecovers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.js")
+	if err := os.WriteFile(path, []byte("if (a) {\n  b()\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fi := FileInfo{Path: path, RelPath: "a.js", Language: "JavaScript"}
+	got := analyzeFile(nil, panicAnalyzer{}, fi)
+	if want := analyzeGeneric(path); got.Total != want.Total || got.Lines != want.Lines {
+		t.Errorf("analyzeFile = %+v, want the generic result %+v", got, want)
+	}
+	if got.Fallback != "the panic analyzer failed: boom" {
+		t.Errorf("Fallback = %q", got.Fallback)
+	}
+}
@@ -0,0 +1,194 @@
+package analyze
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// cacheVersion is stored in every cache file. Bump it whenever an analyzer
+// or the History layout changes so stale entries are discarded.
+const cacheVersion = 11
+
+// Cache is a persistent store of analysis results for one scan root.
+// Complexity is keyed by the git blob hash of each file's content and the
+// history by the HEAD commit and .mailmap, so unchanged files and an
+// unmoved HEAD skip re-analysis. A nil *Cache is valid and caches nothing.
+type Cache struct {
+	dir string
+
+	// old holds the entries loaded from disk and is read-only after
+	// OpenCache; fresh collects this scan's entries, which replace old on
+	// Save so files that disappeared are pruned.
+	old   map[string]ComplexityResult
+	mu    sync.Mutex
+	fresh map[string]ComplexityResult
+}
+
+type complexityCacheFile struct {
+	Version int                         `json:"version"`
+	Entries map[string]ComplexityResult `json:"entries"`
+}
+
+type historyCacheFile struct {
+	Version int      `json:"version"`
+	Key     string   `json:"key"`
+	History *History `json:"history"`
+}
+
+// OpenCache opens the cache for root inside baseDir, creating it if
+// needed. A missing or outdated cache file is treated as empty.
+func OpenCache(baseDir, root string) (*Cache, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	dir := filepath.Join(baseDir, hex.EncodeToString(sum[:8]))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		dir:   dir,
+		old:   make(map[string]ComplexityResult),
+		fresh: make(map[string]ComplexityResult),
+	}
+	var f complexityCacheFile
+	if readCacheFile(filepath.Join(dir, "complexity.json"), &f) && f.Version == cacheVersion {
+		c.old = f.Entries
+	}
+	return c, nil
+}
+
+// complexity returns the complexity of fi as computed by a, reusing a
+// cached result when the file content has not changed.
+func (c *Cache) complexity(fi FileInfo, a Analyzer) ComplexityResult {
+	if c == nil {
+		return a.Analyze(fi)
+	}
+
+	key, err := blobHash(fi.Path)
+	if err != nil {
+		return a.Analyze(fi)
+	}
+	key = fi.Language + ":" + a.Name() + ":" + key
+
+	res, ok := c.old[key]
+	if !ok {
+		res = a.Analyze(fi)
+	}
+	c.mu.Lock()
+	c.fresh[key] = res
+	c.mu.Unlock()
+	return res
+}
+
+// history returns the history of root, reusing the cached copy when
+// neither HEAD nor .mailmap has changed since it was stored.
+func (c *Cache) history(ctx context.Context, root string) (*History, error) {
+	if c == nil {
+		return LoadHistory(ctx, root)
+	}
+
+	path := filepath.Join(c.dir, "history.json")
+	_, head, err := gitHead(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if head == "" {
+		return LoadHistory(ctx, root)
+	}
+	key := historyKey(root, head)
+	var cached historyCacheFile
+	if readCacheFile(path, &cached) && cached.Version == cacheVersion &&
+		cached.History != nil && cached.Key == key {
+		cached.History.index()
+		return cached.History, nil
+	}
+
+	h, err := LoadHistory(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	if h.Head == head {
+		_ = writeCacheFile(path, historyCacheFile{Version: cacheVersion, Key: key, History: h})
+	}
+	return h, nil
+}
+
+// historyKey identifies what the history of root is read from: HEAD and
+// the .mailmap that git log applies to author names.
+func historyKey(root, head string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "head %s\x00", head)
+	if abs, err := filepath.Abs(root); err == nil {
+		if repo := findRepoRoot(abs); repo != "" {
+			data, _ := os.ReadFile(filepath.Join(repo, ".mailmap")) // a missing file counts as empty
+			h.Write(data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Save writes this scan's complexity results to disk.
+func (c *Cache) Save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return writeCacheFile(filepath.Join(c.dir, "complexity.json"),
+		complexityCacheFile{Version: cacheVersion, Entries: c.fresh})
+}
+
+// blobHash returns the git blob hash of the file at path, the same value
+// `git hash-object` prints.
+func blobHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(data))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCacheFile decodes path into v and reports whether it succeeded.
+func readCacheFile(path string, v any) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// writeCacheFile encodes v to path atomically, so a concurrent or
+// interrupted scan never leaves a truncated file behind.
+func writeCacheFile(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -1,75 +1,368 @@
 package analyze
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// ChurnResult holds churn analysis for a file.
+// ChurnResult holds churn analysis for a file. Every figure only counts
+// commits inside the Period it was computed for.
 type ChurnResult struct {
-	TotalCommits   int
-	MonthlyBuckets []int // last 12 months, oldest first
-	IsGitRepo      bool
+	TotalCommits int
+	// Buckets count the commits in each of the ChurnBuckets buckets of the
+	// period's granularity that end at its Until (or now), oldest first.
+	Buckets   []int
+	IsGitRepo bool
+
+	// LinesAdded and LinesDeleted sum the lines changed by every commit.
+	// Binary changes count as zero lines.
+	LinesAdded   int
+	LinesDeleted int
+	// FixCommits counts the commits classified as bug fixes; see
+	// FixRules. The rest are features and other work.
+	FixCommits int
+	// Decayed weighs every commit by its age: 1 at the end of the period,
+	// halving with every half-life before it. A file that stopped changing
+	// long ago scores near zero however busy it once was.
+	Decayed float64
+	// RelativeChurn is LinesChurned divided by the file's current line
+	// count. AnalyzeChurn cannot see the file's size, so Score fills it in.
+	RelativeChurn float64
+
+	// Authors are everyone who changed the file, main owner first.
+	Authors []AuthorShare
+	// BusFactor is the smallest number of authors who together made more
+	// than half of the changes, by lines or, for files without line
+	// changes, by commits. 1 means a single person dominates the file.
+	BusFactor int
+
+	// Coupled are the files that change together with this one, most
+	// strongly coupled first; see History.Couplings. Scan fills it in.
+	Coupled []CoupledFile
 }
 
-// AnalyzeChurn runs git log to measure how often a file has changed.
-func AnalyzeChurn(fi FileInfo, root string) ChurnResult {
-	// Check if this is a git repo
-	checkCmd := exec.Command("git", "-C", root, "rev-parse", "--git-dir")
-	if err := checkCmd.Run(); err != nil {
-		return ChurnResult{IsGitRepo: false}
+// AuthorShare is one author's part in a file's history. Authors are
+// identified by email, after .mailmap is applied.
+type AuthorShare struct {
+	Name        string
+	Email       string
+	Commits     int
+	Lines       int     // lines added plus deleted
+	CommitShare float64 // 0–1 of the file's commits
+	LineShare   float64 // 0–1 of the file's churned lines
+}
+
+// Owner returns the author with the largest share of the file, if any.
+func (c ChurnResult) Owner() (AuthorShare, bool) {
+	if len(c.Authors) == 0 {
+		return AuthorShare{}, false
 	}
+	return c.Authors[0], true
+}
 
-	// Get total commit count for this file
-	totalOut, err := exec.Command(
-		"git", "-C", root, "log", "--follow", "--oneline", "--", fi.Path,
-	).Output()
-	if err != nil {
-		return ChurnResult{IsGitRepo: true}
+// LinesChurned returns the total number of lines added and deleted.
+func (c ChurnResult) LinesChurned() int {
+	return c.LinesAdded + c.LinesDeleted
+}
+
+// NetLines returns how much the file grew, negative if it shrank.
+func (c ChurnResult) NetLines() int {
+	return c.LinesAdded - c.LinesDeleted
+}
+
+// History is the git history of a repository, collected in a single
+// `git log` pass. File paths are relative to the scan root and use the
+// name each file has at HEAD, so renames are already followed.
+type History struct {
+	IsGitRepo bool
+	Head      string   // commit hash of HEAD, empty without commits
+	Commits   []Commit // newest first
+
+	// byPath indexes Commits by file path.
+	byPath map[string][]fileRef
+}
+
+// fileRef locates a file's change: Commits[commit].Files[file].
+type fileRef struct {
+	commit, file int
+}
+
+// Commit is a single commit in a History.
+type Commit struct {
+	Hash string
+	Time int64 // committer time, Unix seconds
+	// Author and Email identify the author after .mailmap is applied.
+	Author  string
+	Email   string
+	Subject string // first line of the message
+	Merge   bool   // if so, Files are the changes against the first parent
+	Files   []FileChange
+
+	// Fix reports whether the commit fixes a bug, as decided by the last
+	// call to ClassifyFixes.
+	Fix bool `json:"-"`
+}
+
+// FileChange is a file touched by a Commit.
+type FileChange struct {
+	Path    string // as named at HEAD
+	Added   int    // lines added, 0 for binary files
+	Deleted int    // lines deleted, 0 for binary files
+}
+
+// Field and record separators used in the git log format. They cannot
+// appear in commit hashes or timestamps.
+const (
+	logRecordSep = '\x1e'
+	logFieldSep  = '\x1f'
+)
+
+// gitHead reports whether root is inside a git repository and, if the
+// repository has commits, the hash of HEAD.
+func gitHead(ctx context.Context, root string) (isRepo bool, head string, err error) {
+	if err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--git-dir").Run(); err != nil {
+		return false, "", ctx.Err()
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(totalOut)), "\n")
-	total := 0
-	if lines[0] != "" {
-		total = len(lines)
+	// A repository without commits has no HEAD to resolve.
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--verify", "-q", "HEAD").Output()
+	if err != nil {
+		return true, "", ctx.Err()
 	}
+	return true, strings.TrimSpace(string(out)), nil
+}
 
-	// Build monthly buckets for last 12 months
-	buckets := make([]int, 12)
-	now := time.Now()
+// LoadHistory streams `git log --numstat` once for the whole
+// repository containing root. A directory that is not inside a git
+// repository yields an empty History rather than an error.
+func LoadHistory(ctx context.Context, root string) (*History, error) {
+	isRepo, head, err := gitHead(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	h := &History{IsGitRepo: isRepo, Head: head}
+	if head == "" {
+		h.index()
+		return h, nil
+	}
 
-	for month := 0; month < 12; month++ {
-		// month 0 = 12 months ago, month 11 = current month
-		after := now.AddDate(0, -(12 - month), 0)
-		before := now.AddDate(0, -(11 - month), 0)
+	// --relative restricts the log to root and makes paths relative to it.
+	// -z keeps unusual file names unquoted. --cc lists the files of merge
+	// commits, with their changes against the first parent, so that
+	// ChurnFilters can decide whether merges count.
+	cmd := exec.CommandContext(
+		ctx, "git", "-C", root, "log",
+		"--relative", "-M", "--numstat", "-z", "--cc",
+		"--format="+string(logRecordSep)+strings.Join([]string{"%H", "%P", "%ct", "%aN", "%aE", "%s"}, string(logFieldSep)),
+		head,
+	)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
 
-		afterStr := after.Format("2006-01-02")
-		beforeStr := before.Format("2006-01-02")
+	// renamed maps a historical path to the path the file has at HEAD.
+	// The log runs newest first, so a rename is always seen before the
+	// older commits that used the old name.
+	renamed := make(map[string]string)
+	current := func(path string) string {
+		if p, ok := renamed[path]; ok {
+			return p
+		}
+		return path
+	}
 
-		out, err := exec.Command(
-			"git", "-C", root, "log",
-			fmt.Sprintf("--after=%s", afterStr),
-			fmt.Sprintf("--before=%s", beforeStr),
-			"--oneline", "--follow", "--", fi.Path,
-		).Output()
+	sc := bufio.NewScanner(out)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	sc.Split(splitRecords)
+	for sc.Scan() {
+		header, body, _ := strings.Cut(sc.Text(), "\x00")
+		meta := strings.SplitN(header, string(logFieldSep), 6)
+		if len(meta) != 6 {
+			continue
+		}
+		secs, err := strconv.ParseInt(meta[2], 10, 64)
 		if err != nil {
 			continue
 		}
-		countStr := strings.TrimSpace(string(out))
-		if countStr == "" {
-			buckets[month] = 0
-		} else {
-			buckets[month] = len(strings.Split(countStr, "\n"))
+		c := Commit{
+			Hash:    meta[0],
+			Time:    secs,
+			Author:  meta[3],
+			Email:   meta[4],
+			Subject: meta[5],
+			Merge:   strings.Contains(meta[1], " "),
+		}
+
+		// Each file is "added\tdeleted\tpath"; a rename leaves the path
+		// empty and is followed by the old and the new path.
+		fields := strings.Split(strings.TrimLeft(body, "\n"), "\x00")
+		for i := 0; i < len(fields); i++ {
+			parts := strings.SplitN(strings.TrimLeft(fields[i], "\n"), "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(parts[0]) // "-" for binary files
+			deleted, _ := strconv.Atoi(parts[1])
+			path := parts[2]
+			if path == "" {
+				if i+2 >= len(fields) {
+					break
+				}
+				oldPath, newPath := fields[i+1], fields[i+2]
+				i += 2
+				path = current(newPath)
+				renamed[oldPath] = path
+			} else {
+				path = current(path)
+			}
+			c.Files = append(c.Files, FileChange{Path: path, Added: added, Deleted: deleted})
 		}
-		_ = strconv.Itoa(0) // keep import
+		if len(c.Files) > 0 {
+			h.Commits = append(h.Commits, c)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("git log: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	h.index()
+	return h, nil
+}
+
+// index builds the per-path lookup table from Commits.
+func (h *History) index() {
+	h.byPath = make(map[string][]fileRef)
+	for i, c := range h.Commits {
+		for j, f := range c.Files {
+			h.byPath[f.Path] = append(h.byPath[f.Path], fileRef{i, j})
+		}
+	}
+}
+
+// splitRecords is a bufio.SplitFunc that splits on logRecordSep.
+func splitRecords(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, logRecordSep); i >= 0 {
+		if i == 0 {
+			return 1, nil, nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// AnalyzeChurn looks up how often a file has changed during p.
+func AnalyzeChurn(fi FileInfo, h *History, p Period) ChurnResult {
+	if h == nil || !h.IsGitRepo {
+		return ChurnResult{IsGitRepo: false}
+	}
+
+	path := filepath.ToSlash(fi.RelPath)
+	now := time.Now()
+	bounds := p.bucketBounds(now)
+	res := ChurnResult{
+		Buckets:   make([]int, len(bounds)-1),
+		IsGitRepo: true,
 	}
+	authors := make(map[string]*AuthorShare)
+	for _, ref := range h.byPath[path] {
+		c := h.Commits[ref.commit]
+		if !p.contains(c.Time) {
+			continue
+		}
+		res.TotalCommits++
+		if c.Fix {
+			res.FixCommits++
+		}
+		res.Decayed += p.decay(c.Time, now)
+		when := time.Unix(c.Time, 0)
+		for b := range res.Buckets {
+			if when.After(bounds[b]) && !when.After(bounds[b+1]) {
+				res.Buckets[b]++
+				break
+			}
+		}
 
-	return ChurnResult{
-		TotalCommits:   total,
-		MonthlyBuckets: buckets,
-		IsGitRepo:      true,
+		key := strings.ToLower(c.Email)
+		if key == "" {
+			key = c.Author
+		}
+		a, ok := authors[key]
+		if !ok {
+			a = &AuthorShare{Name: c.Author, Email: c.Email}
+			authors[key] = a
+		}
+		a.Commits++
+		f := c.Files[ref.file]
+		res.LinesAdded += f.Added
+		res.LinesDeleted += f.Deleted
+		a.Lines += f.Added + f.Deleted
+	}
+	res.Authors, res.BusFactor = authorShares(authors, res.TotalCommits, res.LinesChurned())
+	return res
+}
+
+// authorShares turns per-author totals into shares, sorted by lines, then
+// commits, then name, and computes the bus factor.
+func authorShares(authors map[string]*AuthorShare, commits, lines int) ([]AuthorShare, int) {
+	if len(authors) == 0 {
+		return nil, 0
+	}
+	out := make([]AuthorShare, 0, len(authors))
+	for _, a := range authors {
+		a.CommitShare = float64(a.Commits) / float64(commits)
+		if lines > 0 {
+			a.LineShare = float64(a.Lines) / float64(lines)
+		}
+		out = append(out, *a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Lines != out[j].Lines {
+			return out[i].Lines > out[j].Lines
+		}
+		if out[i].Commits != out[j].Commits {
+			return out[i].Commits > out[j].Commits
+		}
+		return out[i].Name < out[j].Name
+	})
+
+	// Without line changes, rank and count by commits instead. The slice
+	// is then already in commit order, as every author has zero lines.
+	share := func(a AuthorShare) float64 { return a.LineShare }
+	if lines == 0 {
+		share = func(a AuthorShare) float64 { return a.CommitShare }
+	}
+	factor, covered := 0, 0.0
+	for _, a := range out {
+		factor++
+		covered += share(a)
+		if covered > 0.5 {
+			break
+		}
 	}
+	return out, factor
 }
@@ -0,0 +1,172 @@
+package analyze
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// cognitiveComplexity computes the Sonar-style cognitive complexity of a Go
+// function:
+//
+//   - +1 for each if, else if, else, switch, select, for and range, plus
+//     the current nesting level for all but else/else if
+//   - +1 for each sequence of like boolean operators (a && b && c is one,
+//     a && b || c is two)
+//   - +1 for each goto and each labeled break or continue
+//   - +1 for each direct recursive call
+//
+// Nesting rises inside the bodies of those statements. Function literals
+// are skipped unless closures is set, in which case they count as part of
+// the function one nesting level deeper. name and recv are the function's
+// name and receiver, for spotting recursion.
+func cognitiveComplexity(body *ast.BlockStmt, name, recv string, closures bool) int {
+	c := &cognitiveCounter{
+		name:     name,
+		recv:     recv,
+		closures: closures,
+		counted:  make(map[*ast.BinaryExpr]bool),
+	}
+	c.visit(body, 0)
+	return c.score
+}
+
+type cognitiveCounter struct {
+	name     string // function name, for spotting recursion; "" for literals
+	recv     string // receiver name for methods, "" for functions
+	closures bool   // score function literals as part of the function
+	score    int
+	counted  map[*ast.BinaryExpr]bool // operands of an already-scored sequence
+}
+
+// visit scores n and everything below it at the given nesting level.
+func (c *cognitiveCounter) visit(n ast.Node, nesting int) {
+	if n == nil {
+		return
+	}
+	ast.Inspect(n, func(x ast.Node) bool {
+		switch x := x.(type) {
+		case *ast.IfStmt:
+			c.score += 1 + nesting
+			c.ifStmt(x, nesting)
+			return false
+
+		case *ast.ForStmt:
+			c.score += 1 + nesting
+			c.visit(x.Init, nesting)
+			c.visit(x.Cond, nesting)
+			c.visit(x.Post, nesting)
+			c.visit(x.Body, nesting+1)
+			return false
+
+		case *ast.RangeStmt:
+			c.score += 1 + nesting
+			c.visit(x.X, nesting)
+			c.visit(x.Body, nesting+1)
+			return false
+
+		case *ast.SwitchStmt:
+			c.score += 1 + nesting
+			c.visit(x.Init, nesting)
+			c.visit(x.Tag, nesting)
+			c.visit(x.Body, nesting+1)
+			return false
+
+		case *ast.TypeSwitchStmt:
+			c.score += 1 + nesting
+			c.visit(x.Init, nesting)
+			c.visit(x.Assign, nesting)
+			c.visit(x.Body, nesting+1)
+			return false
+
+		case *ast.SelectStmt:
+			c.score += 1 + nesting
+			c.visit(x.Body, nesting+1)
+			return false
+
+		case *ast.FuncLit:
+			if c.closures {
+				c.visit(x.Body, nesting+1)
+			}
+			return false
+
+		case *ast.BranchStmt:
+			if x.Tok == token.GOTO || (x.Label != nil && (x.Tok == token.BREAK || x.Tok == token.CONTINUE)) {
+				c.score++
+			}
+
+		case *ast.BinaryExpr:
+			if isLogical(x.Op) && !c.counted[x] {
+				c.score += c.logicalSequences(x)
+			}
+
+		case *ast.CallExpr:
+			if c.isRecursive(x) {
+				c.score++
+			}
+		}
+		return true
+	})
+}
+
+// ifStmt scores the parts of an if statement whose own increment has
+// already been added, following else-if chains without extra nesting.
+func (c *cognitiveCounter) ifStmt(x *ast.IfStmt, nesting int) {
+	c.visit(x.Init, nesting)
+	c.visit(x.Cond, nesting)
+	c.visit(x.Body, nesting+1)
+
+	switch e := x.Else.(type) {
+	case *ast.IfStmt:
+		c.score++ // else if
+		c.ifStmt(e, nesting)
+	case *ast.BlockStmt:
+		c.score++ // else
+		c.visit(e, nesting+1)
+	}
+}
+
+// logicalSequences counts the runs of like operators in a chain of && and
+// ||, and marks the chain so that its inner nodes are not counted again.
+func (c *cognitiveCounter) logicalSequences(x *ast.BinaryExpr) int {
+	var ops []token.Token
+	var flatten func(e ast.Expr)
+	flatten = func(e ast.Expr) {
+		switch e := e.(type) {
+		case *ast.ParenExpr:
+			flatten(e.X)
+		case *ast.BinaryExpr:
+			if !isLogical(e.Op) {
+				return
+			}
+			c.counted[e] = true
+			flatten(e.X)
+			ops = append(ops, e.Op)
+			flatten(e.Y)
+		}
+	}
+	flatten(x)
+
+	n := 0
+	for i, op := range ops {
+		if i == 0 || op != ops[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+// isRecursive reports whether call invokes the function being scored.
+func (c *cognitiveCounter) isRecursive(call *ast.CallExpr) bool {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return c.name != "" && c.recv == "" && fn.Name == c.name
+	case *ast.SelectorExpr:
+		id, ok := fn.X.(*ast.Ident)
+		return ok && c.recv != "" && id.Name == c.recv && fn.Sel.Name == c.name
+	}
+	return false
+}
+
+func isLogical(op token.Token) bool {
+	return op == token.LAND || op == token.LOR
+}
@@ -2,10 +2,13 @@ package analyze
 
 import (
 	"bufio"
+	"bytes"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -13,64 +16,247 @@ import (
 type FuncComplexity struct {
 	Name       string
 	Complexity int
+	Cognitive  int
 	Line       int
+	// Package is the import path of the function's package, for
+	// languages that have one; see ID.
+	Package string `json:",omitempty"`
 }
 
-// ComplexityResult holds complexity analysis for a file.
+// ID returns an identity for the function that is unique across the
+// repository where the language allows: "pkg/path.Type.Method" for Go.
+func (f FuncComplexity) ID() string {
+	if f.Package == "" {
+		return f.Name
+	}
+	return f.Package + "." + f.Name
+}
+
+// ComplexityResult holds complexity analysis for a file. Total is the
+// cyclomatic complexity and Cognitive the sum of the functions' cognitive
+// complexity.
 type ComplexityResult struct {
 	Total     int
+	Cognitive int
 	Functions []FuncComplexity
+	Lines     int // lines in the file, blank and comment lines included
+	// Fallback says why the generic analyzer stood in for the file's own,
+	// if it did.
+	Fallback string `json:",omitempty"`
 }
 
-// AnalyzeComplexity returns the cyclomatic complexity of a file.
-func AnalyzeComplexity(fi FileInfo) ComplexityResult {
-	switch fi.Language {
-	case "Go":
-		return analyzeGo(fi.Path)
-	default:
-		return analyzeGeneric(fi.Path)
+// Value returns the file's complexity under metric m.
+func (r ComplexityResult) Value(m ComplexityMetric) int {
+	if m == MetricCognitive {
+		return r.Cognitive
 	}
+	return r.Total
+}
+
+// GoAnalyzer analyzes Go source with go/ast. Every function, method and
+// function literal is reported on its own. Literals are named after the
+// function containing them the way the Go runtime names them, e.g.
+// "Serve.func1" and, nested inside it, "Serve.func1.1"; a literal assigned
+// to a package-level variable takes the variable's name.
+type GoAnalyzer struct {
+	// ClosuresInParent also counts each function literal towards the
+	// function containing it. File totals count every literal once either
+	// way.
+	ClosuresInParent bool
+}
+
+// Name returns "go", or "go+closures" with ClosuresInParent set.
+func (a GoAnalyzer) Name() string {
+	if a.ClosuresInParent {
+		return "go+closures"
+	}
+	return "go"
+}
+
+func (a GoAnalyzer) Analyze(fi FileInfo) ComplexityResult {
+	return analyzeGo(fi.Path, a.ClosuresInParent)
 }
 
 // analyzeGo uses Go's AST to compute precise cyclomatic complexity.
-func analyzeGo(path string) ComplexityResult {
+func analyzeGo(path string, closuresInParent bool) ComplexityResult {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return ComplexityResult{Total: 1}
+	}
 	fset := token.NewFileSet()
-	f, err := parser.ParseFile(fset, path, nil, 0)
+	f, err := parser.ParseFile(fset, path, src, 0)
 	if err != nil {
 		return analyzeGeneric(path)
 	}
 
-	var funcs []FuncComplexity
-
+	g := &goFuncs{fset: fset, closuresInParent: closuresInParent, total: 1}
 	for _, decl := range f.Decls {
-		fd, ok := decl.(*ast.FuncDecl)
-		if !ok || fd.Body == nil {
-			continue
-		}
-		name := fd.Name.Name
-		if fd.Recv != nil && len(fd.Recv.List) > 0 {
-			if t, ok2 := fd.Recv.List[0].Type.(*ast.StarExpr); ok2 {
-				if id, ok3 := t.X.(*ast.Ident); ok3 {
-					name = id.Name + "." + name
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body == nil {
+				continue
+			}
+			recv := ""
+			if d.Recv != nil && len(d.Recv.List) > 0 && len(d.Recv.List[0].Names) > 0 {
+				recv = d.Recv.List[0].Names[0].Name
+			}
+			g.add(goFuncName(d), d.Body, d.Pos(), d.Name.Name, recv, false)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, v := range vs.Values {
+					name := "_"
+					if i < len(vs.Names) {
+						name = vs.Names[i].Name
+					}
+					if lit, ok := v.(*ast.FuncLit); ok {
+						g.add(name, lit.Body, lit.Pos(), "", "", false)
+					} else {
+						g.literals(name, v, false)
+					}
 				}
-			} else if id, ok2 := fd.Recv.List[0].Type.(*ast.Ident); ok2 {
-				name = id.Name + "." + name
 			}
 		}
-		c := countComplexity(fd.Body)
-		line := fset.Position(fd.Pos()).Line
-		funcs = append(funcs, FuncComplexity{Name: name, Complexity: c, Line: line})
 	}
 
-	total := 1
-	for _, fn := range funcs {
-		total += fn.Complexity - 1
+	sortFunctions(g.funcs)
+	return ComplexityResult{Total: g.total, Cognitive: g.cognitive, Functions: g.funcs, Lines: countLines(src)}
+}
+
+// goFuncName returns the name of a function declaration, prefixed with
+// its receiver type for methods: "Set.Add" for func (s *Set[T]) Add.
+func goFuncName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	expr := fd.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr: // one type parameter
+			expr = t.X
+		case *ast.IndexListExpr: // several type parameters
+			expr = t.X
+		case *ast.Ident:
+			return t.Name + "." + fd.Name.Name
+		default:
+			return fd.Name.Name
+		}
+	}
+}
+
+// Qualify sets the package of every function in res to the import path
+// of the directory holding fi: the module path from the nearest go.mod
+// plus the directory relative to it. Outside a module the path relative
+// to the scan root is used.
+func (a GoAnalyzer) Qualify(fi FileInfo, res *ComplexityResult) {
+	pkg := goPackagePath(fi)
+	for i := range res.Functions {
+		res.Functions[i].Package = pkg
+	}
+}
+
+// goPackagePath returns the import path of the package containing fi.
+func goPackagePath(fi FileInfo) string {
+	dir := filepath.Dir(fi.Path)
+	for d := dir; ; {
+		if mod := goModulePath(filepath.Join(d, "go.mod")); mod != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil || rel == "." {
+				return mod
+			}
+			return mod + "/" + filepath.ToSlash(rel)
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			break
+		}
+		d = parent
+	}
+	if rel := filepath.ToSlash(filepath.Dir(fi.RelPath)); rel != "." {
+		return rel
+	}
+	return ""
+}
+
+// goModulePath returns the module path declared in the go.mod at path, or
+// "" if there is none.
+func goModulePath(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
 	}
-	if len(funcs) == 0 {
-		total = 1
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			rest, _, _ = strings.Cut(rest, "//")
+			return strings.Trim(strings.TrimSpace(rest), `"`+"`")
+		}
+	}
+	return ""
+}
+
+// goFuncs collects the functions of one Go file.
+type goFuncs struct {
+	fset             *token.FileSet
+	closuresInParent bool
+
+	funcs     []FuncComplexity
+	total     int // file cyclomatic complexity, each decision counted once
+	cognitive int
+}
+
+// add records the function with the given body, then each function
+// literal inside it. ident and recv are the declared and receiver names
+// used to spot recursion; closure tells whether the function is itself a
+// literal, which decides how its own literals are numbered.
+func (g *goFuncs) add(name string, body *ast.BlockStmt, pos token.Pos, ident, recv string, closure bool) {
+	own := countComplexity(body, false)
+	ownCognitive := cognitiveComplexity(body, ident, recv, false)
+	g.total += own - 1
+	g.cognitive += ownCognitive
+
+	fn := FuncComplexity{Name: name, Complexity: own, Cognitive: ownCognitive, Line: g.fset.Position(pos).Line}
+	if g.closuresInParent {
+		fn.Complexity = countComplexity(body, true)
+		fn.Cognitive = cognitiveComplexity(body, ident, recv, true)
 	}
+	g.funcs = append(g.funcs, fn)
 
-	// Sort funcs by complexity descending (simple bubble sort, small slices)
+	g.literals(name, body, closure)
+}
+
+// literals adds the function literals in n that are not nested in another
+// literal, named prefix.funcN, or prefix.N when prefix is a literal.
+func (g *goFuncs) literals(prefix string, n ast.Node, closure bool) {
+	i := 0
+	ast.Inspect(n, func(x ast.Node) bool {
+		lit, ok := x.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		i++
+		name := fmt.Sprintf("%s.func%d", prefix, i)
+		if closure {
+			name = fmt.Sprintf("%s.%d", prefix, i)
+		}
+		g.add(name, lit.Body, lit.Pos(), "", "", true)
+		return false
+	})
+}
+
+// sortFunctions sorts funcs by complexity descending (simple bubble sort,
+// small slices).
+func sortFunctions(funcs []FuncComplexity) {
 	for i := 0; i < len(funcs)-1; i++ {
 		for j := i + 1; j < len(funcs); j++ {
 			if funcs[j].Complexity > funcs[i].Complexity {
@@ -78,15 +264,16 @@ func analyzeGo(path string) ComplexityResult {
 			}
 		}
 	}
-
-	return ComplexityResult{Total: total, Functions: funcs}
 }
 
-// countComplexity visits an AST node and counts decision points.
-func countComplexity(node ast.Node) int {
+// countComplexity visits an AST node and counts decision points,
+// including those inside function literals when closures is set.
+func countComplexity(node ast.Node, closures bool) int {
 	count := 1
 	ast.Inspect(node, func(n ast.Node) bool {
 		switch n := n.(type) {
+		case *ast.FuncLit:
+			return closures
 		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt,
 			*ast.CaseClause, *ast.CommClause, *ast.SelectStmt:
 			_ = n
@@ -101,7 +288,10 @@ func countComplexity(node ast.Node) int {
 	return count
 }
 
-// analyzeGeneric uses line-based heuristics for non-Go files.
+// analyzeGeneric uses line-based heuristics for non-Go files. Cognitive
+// complexity is estimated by weighting each decision line by its
+// indentation, taking the shallowest indented line as one level and
+// assuming function bodies sit one level deep.
 func analyzeGeneric(path string) ComplexityResult {
 	f, err := os.Open(path)
 	if err != nil {
@@ -111,19 +301,67 @@ func analyzeGeneric(path string) ComplexityResult {
 
 	keywords := []string{"if ", "else ", "elif ", "for ", "while ", "case ", "catch ", "&&", "||", "? "}
 
-	count := 1
+	count, lines := 1, 0
+	var indents []int // indentation of each decision line
+	unit := 0         // smallest non-zero indentation seen
 	scanner := bufio.NewScanner(f)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+		lines++
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		indent := indentWidth(raw)
+		if indent > 0 && (unit == 0 || indent < unit) {
+			unit = indent
+		}
 		if strings.HasPrefix(line, "//") || strings.HasPrefix(line, "#") {
 			continue
 		}
 		for _, kw := range keywords {
 			if strings.Contains(line, kw) {
 				count++
+				indents = append(indents, indent)
 				break
 			}
 		}
 	}
-	return ComplexityResult{Total: count}
+
+	cognitive := 0
+	for _, indent := range indents {
+		nesting := 0
+		if unit > 0 && indent/unit > 1 {
+			nesting = indent/unit - 1
+		}
+		cognitive += 1 + nesting
+	}
+	return ComplexityResult{Total: count, Cognitive: cognitive, Lines: lines}
+}
+
+// countLines returns the number of lines in src, counting a final line
+// without a trailing newline.
+func countLines(src []byte) int {
+	n := bytes.Count(src, []byte("\n"))
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// indentWidth returns the width of line's leading whitespace, counting a
+// tab as four columns.
+func indentWidth(line string) int {
+	w := 0
+	for _, c := range line {
+		switch c {
+		case ' ':
+			w++
+		case '\t':
+			w += 4
+		default:
+			return w
+		}
+	}
+	return w
 }
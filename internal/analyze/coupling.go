@@ -0,0 +1,137 @@
+package analyze
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// CouplingOptions configures temporal coupling analysis. Zero fields use
+// the values in DefaultCouplingOptions.
+type CouplingOptions struct {
+	// MinShared is the number of commits two files must share before they
+	// count as coupled.
+	MinShared int
+	// MaxFiles skips commits touching more files than this, such as
+	// reformats and vendoring, which couple everything to everything.
+	MaxFiles int
+}
+
+// DefaultCouplingOptions are the coupling settings used when none are
+// configured.
+var DefaultCouplingOptions = CouplingOptions{MinShared: 3, MaxFiles: 50}
+
+func (o CouplingOptions) withDefaults() CouplingOptions {
+	if o.MinShared <= 0 {
+		o.MinShared = DefaultCouplingOptions.MinShared
+	}
+	if o.MaxFiles <= 0 {
+		o.MaxFiles = DefaultCouplingOptions.MaxFiles
+	}
+	return o
+}
+
+// Coupling is a pair of files that tend to change in the same commits.
+type Coupling struct {
+	A, B     string // slash-separated paths, A < B
+	Shared   int    // commits touching both files
+	CommitsA int    // commits touching A
+	CommitsB int    // commits touching B
+	// Degree is Shared divided by the average of CommitsA and CommitsB:
+	// 1 means the files only ever change together.
+	Degree float64
+}
+
+// CoupledFile is a file's partner in a Coupling.
+type CoupledFile struct {
+	Path   string
+	Shared int
+	Degree float64
+}
+
+// Couplings returns the pairs among files that changed together in at
+// least opts.MinShared commits during p, most strongly coupled first.
+// Commits with more than opts.MaxFiles files are ignored, and so are files
+// outside files; commit counts only include the commits that were
+// considered.
+func (h *History) Couplings(files []FileInfo, p Period, opts CouplingOptions) []Coupling {
+	if h == nil || !h.IsGitRepo {
+		return nil
+	}
+	opts = opts.withDefaults()
+
+	scanned := make(map[string]bool, len(files))
+	for _, f := range files {
+		scanned[filepath.ToSlash(f.RelPath)] = true
+	}
+
+	type pair struct{ a, b string }
+	commits := make(map[string]int)
+	shared := make(map[pair]int)
+	var paths []string
+	for _, c := range h.Commits {
+		if len(c.Files) > opts.MaxFiles || !p.contains(c.Time) {
+			continue
+		}
+		paths = paths[:0]
+		for _, f := range c.Files {
+			if scanned[f.Path] {
+				paths = append(paths, f.Path)
+			}
+		}
+		sort.Strings(paths)
+		for i, a := range paths {
+			commits[a]++
+			for _, b := range paths[i+1:] {
+				if a != b {
+					shared[pair{a, b}]++
+				}
+			}
+		}
+	}
+
+	var out []Coupling
+	for p, n := range shared {
+		if n < opts.MinShared {
+			continue
+		}
+		ca, cb := commits[p.a], commits[p.b]
+		out = append(out, Coupling{
+			A: p.a, B: p.b,
+			Shared:   n,
+			CommitsA: ca,
+			CommitsB: cb,
+			Degree:   float64(n) / (float64(ca+cb) / 2),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Degree != out[j].Degree {
+			return out[i].Degree > out[j].Degree
+		}
+		if out[i].Shared != out[j].Shared {
+			return out[i].Shared > out[j].Shared
+		}
+		if out[i].A != out[j].A {
+			return out[i].A < out[j].A
+		}
+		return out[i].B < out[j].B
+	})
+	return out
+}
+
+// attachCouplings records each coupling as a partner of both its files in
+// churns, which is indexed like files. Partners keep the order of
+// couplings.
+func attachCouplings(files []FileInfo, churns []ChurnResult, couplings []Coupling) {
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		index[filepath.ToSlash(f.RelPath)] = i
+	}
+	for _, c := range couplings {
+		if i, ok := index[c.A]; ok {
+			churns[i].Coupled = append(churns[i].Coupled, CoupledFile{Path: c.B, Shared: c.Shared, Degree: c.Degree})
+		}
+		if i, ok := index[c.B]; ok {
+			churns[i].Coupled = append(churns[i].Coupled, CoupledFile{Path: c.A, Shared: c.Shared, Degree: c.Degree})
+		}
+	}
+}
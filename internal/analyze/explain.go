@@ -0,0 +1,206 @@
+package analyze
+
+import (
+	"math"
+	"sort"
+)
+
+// ExplainFunctions is the number of functions listed in
+// Explanation.TopFunctions.
+const ExplainFunctions = 3
+
+// Explanation accounts for a file's RiskScore: what each signal
+// contributed, what it was measured against, and what would move the file
+// down a band.
+type Explanation struct {
+	Scorer     string
+	Thresholds Thresholds
+	// Floor is the score at which the file's band starts; the file drops a
+	// band below it. It is 0 in the Low band.
+	Floor      float64
+	Complexity Signal
+	Churn      Signal
+	// TopFunctions are the most complex functions under the complexity
+	// metric, most complex first.
+	TopFunctions []FuncComplexity
+}
+
+// Signal is one input of the risk score.
+type Signal struct {
+	Metric string  // e.g. "cyclomatic" or "commits"
+	Value  float64 // raw, under Metric
+	Norm   float64 // 0–100, as in FileScore
+	Weight float64 // share of the weights, 0–1
+	// Points is how much of the RiskScore the signal accounts for; the
+	// Points of both signals add up to it. Zero unless the scorer is an
+	// Explainer.
+	Points float64
+	// Max is the highest value in the scan and MaxPath a file holding it,
+	// if Max is above 0.
+	Max     float64
+	MaxPath string
+	// Rank is the number of other files whose value is at most Value, out
+	// of Files-1.
+	Rank  int
+	Files int
+	// Below is the value the signal must fall below, with the other signal
+	// and the rest of the scan unchanged, for the file to drop a band; 0
+	// means it must reach 0. Lowerable is false when no value of this
+	// signal alone would do, in the Low band, and unless the scorer is an
+	// Explainer.
+	Below     float64
+	Lowerable bool
+}
+
+// Explainer is implemented by scorers that can account for the scores
+// they give.
+type Explainer interface {
+	// Explain sets Points, and Below and Lowerable where the file is not
+	// in the Low band, on both signals of every file's Explanation. It
+	// runs after Score with the same arguments, once the rest of each
+	// Explanation is filled in.
+	Explain(scores []FileScore, complexity, churn []float64, w Weights)
+}
+
+// Floor returns the score at which band b starts, 0 for RiskLow.
+func (t Thresholds) Floor(b RiskBand) float64 {
+	switch b {
+	case RiskCritical:
+		return t.Critical
+	case RiskHigh:
+		return t.High
+	case RiskMedium:
+		return t.Medium
+	}
+	return 0
+}
+
+// explain fills in the Explanation of every file scored by sc.
+func explain(scores []FileScore, complexity, churn []float64, sc Scorer, w Weights, t Thresholds, opts Options) {
+	dc, dh := newDistribution(complexity), newDistribution(churn)
+	share := w.Complexity / (w.Complexity + w.Churn)
+	for i := range scores {
+		s := &scores[i]
+		s.Explanation = Explanation{
+			Scorer:       sc.Name(),
+			Thresholds:   t,
+			Floor:        t.Floor(s.RiskBand),
+			Complexity:   dc.signal(scores, i, opts.ComplexityMetric.String(), s.ComplexityNorm, share),
+			Churn:        dh.signal(scores, i, opts.ChurnMetric.String(), s.ChurnNorm, 1-share),
+			TopFunctions: topFunctions(s.ComplexityResult.Functions, opts.ComplexityMetric),
+		}
+	}
+	if e, ok := sc.(Explainer); ok {
+		e.Explain(scores, complexity, churn, w)
+	}
+}
+
+// topFunctions returns the ExplainFunctions most complex of funcs under m.
+func topFunctions(funcs []FuncComplexity, m ComplexityMetric) []FuncComplexity {
+	top := append([]FuncComplexity(nil), funcs...)
+	value := func(f FuncComplexity) int {
+		if m == MetricCognitive {
+			return f.Cognitive
+		}
+		return f.Complexity
+	}
+	sort.SliceStable(top, func(i, j int) bool { return value(top[i]) > value(top[j]) })
+	if len(top) > ExplainFunctions {
+		top = top[:ExplainFunctions]
+	}
+	return top
+}
+
+// distribution is one signal's values across a scan.
+type distribution struct {
+	values []float64
+	sorted []float64
+	top    int     // index of a highest value
+	second float64 // highest value among the files other than top
+}
+
+func newDistribution(values []float64) distribution {
+	d := distribution{values: values, sorted: append([]float64(nil), values...)}
+	sort.Float64s(d.sorted)
+	for i, v := range values {
+		if v > values[d.top] {
+			d.top = i
+		}
+	}
+	for i, v := range values {
+		if i != d.top {
+			d.second = math.Max(d.second, v)
+		}
+	}
+	return d
+}
+
+// signal describes file i's value in d.
+func (d distribution) signal(scores []FileScore, i int, metric string, norm, weight float64) Signal {
+	s := Signal{
+		Metric: metric,
+		Value:  d.values[i],
+		Norm:   norm,
+		Weight: weight,
+		Max:    d.values[d.top],
+		Rank:   d.rank(i, d.values[i]),
+		Files:  len(d.values),
+	}
+	if s.Max > 0 {
+		s.MaxPath = scores[d.top].File.RelPath
+	}
+	return s
+}
+
+// othersMax returns the highest value among the files other than i.
+func (d distribution) othersMax(i int) float64 {
+	if i == d.top {
+		return d.second
+	}
+	return d.values[d.top]
+}
+
+// rank returns the number of files other than i whose value is at most v.
+func (d distribution) rank(i int, v float64) int {
+	n := sort.Search(len(d.sorted), func(j int) bool { return d.sorted[j] > v })
+	if d.values[i] <= v {
+		n--
+	}
+	return n
+}
+
+// othersNth returns the k-th smallest value, from 0, among the files
+// other than i.
+func (d distribution) othersNth(i, k int) float64 {
+	if k >= sort.SearchFloat64s(d.sorted, d.values[i]) {
+		k++
+	}
+	return d.sorted[k]
+}
+
+// explainMean explains a scorer whose RiskScore is the weighted mean of
+// the signals. below returns the value of a signal in d whose norm for
+// file i is under limit (0–100); limit is always positive.
+func explainMean(scores []FileScore, complexity, churn []float64, below func(d distribution, i int, limit float64) float64) {
+	dc, dh := newDistribution(complexity), newDistribution(churn)
+	for i := range scores {
+		e := &scores[i].Explanation
+		e.Complexity.Points = e.Complexity.Weight * e.Complexity.Norm
+		e.Churn.Points = e.Churn.Weight * e.Churn.Norm
+		if e.Floor <= 0 {
+			continue
+		}
+		for _, sig := range []struct {
+			own, other *Signal
+			d          distribution
+		}{{&e.Complexity, &e.Churn, dc}, {&e.Churn, &e.Complexity, dh}} {
+			if sig.own.Weight == 0 {
+				continue
+			}
+			limit := (e.Floor - sig.other.Points) / sig.own.Weight
+			if limit > 0 {
+				sig.own.Below, sig.own.Lowerable = below(sig.d, i, limit), true
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package analyze
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ChurnFilters leave commits that are not real work out of churn,
+// coupling and authorship.
+type ChurnFilters struct {
+	// KeepMerges counts merge commits, with their changes against the
+	// first parent. Merges are skipped by default, as the merged commits
+	// already count those changes.
+	KeepMerges bool
+	// IgnoreAuthors skips commits whose "Name <email>" matches one of the
+	// patterns. Nil uses DefaultIgnoreAuthors.
+	IgnoreAuthors []*regexp.Regexp
+	// IgnoreRevsFile lists commits to skip, one hash per line with #
+	// comments, as in `git blame --ignore-revs-file`. Relative paths are
+	// resolved against the top of the repository. Empty uses
+	// DefaultIgnoreRevsFile if it exists.
+	IgnoreRevsFile string
+	// MaxFiles skips commits touching more than this many files under
+	// the scan root, such as mass reformats; 0 keeps them all.
+	MaxFiles int
+}
+
+// DefaultIgnoreAuthors matches the usual dependency and CI bots.
+var DefaultIgnoreAuthors = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\[bot\]|^(dependabot|renovate)\b`),
+}
+
+// DefaultIgnoreRevsFile is the ignore-revs file honored when it exists
+// and no other file is configured.
+const DefaultIgnoreRevsFile = ".git-blame-ignore-revs"
+
+// CompileAuthorPatterns compiles patterns for ChurnFilters.IgnoreAuthors.
+// Patterns match case insensitively.
+func CompileAuthorPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid author pattern %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// Filter returns a copy of h without the commits f leaves out. The
+// History of the repository containing root is expected.
+func (h *History) Filter(ctx context.Context, root string, f ChurnFilters) (*History, error) {
+	if h == nil || !h.IsGitRepo {
+		return h, nil
+	}
+	ignored, err := loadIgnoreRevs(ctx, root, f.IgnoreRevsFile)
+	if err != nil {
+		return nil, err
+	}
+	authors := f.IgnoreAuthors
+	if authors == nil {
+		authors = DefaultIgnoreAuthors
+	}
+
+	out := &History{IsGitRepo: h.IsGitRepo, Head: h.Head}
+	for _, c := range h.Commits {
+		if (c.Merge && !f.KeepMerges) ||
+			(f.MaxFiles > 0 && len(c.Files) > f.MaxFiles) ||
+			ignored.match(c.Hash) ||
+			matchesAny(authors, c.Author+" <"+c.Email+">") {
+			continue
+		}
+		out.Commits = append(out.Commits, c)
+	}
+	out.index()
+	return out, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoreRevs is the set of commits listed in an ignore-revs file.
+type ignoreRevs struct {
+	full   map[string]bool
+	prefix []string // abbreviated hashes
+}
+
+func (r ignoreRevs) match(hash string) bool {
+	if r.full[hash] {
+		return true
+	}
+	for _, p := range r.prefix {
+		if strings.HasPrefix(hash, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIgnoreRevs reads the ignore-revs file at path, or the default file
+// if path is empty. Only an explicitly named file must exist.
+func loadIgnoreRevs(ctx context.Context, root, path string) (ignoreRevs, error) {
+	revs := ignoreRevs{full: make(map[string]bool)}
+	explicit := path != ""
+	if !explicit {
+		path = DefaultIgnoreRevsFile
+	}
+	if !filepath.IsAbs(path) {
+		out, err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--show-toplevel").Output()
+		if err != nil {
+			return revs, ctx.Err()
+		}
+		path = filepath.Join(strings.TrimSpace(string(out)), path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return revs, nil
+		}
+		return revs, fmt.Errorf("ignore-revs file: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		line = strings.ToLower(strings.TrimSpace(line))
+		switch {
+		case line == "":
+		case !isHex(line) || len(line) < 7:
+			return revs, fmt.Errorf("ignore-revs file %s: %q is not a commit hash", path, line)
+		case len(line) == 40 || len(line) == 64: // SHA-1 or SHA-256
+			revs.full[line] = true
+		default:
+			revs.prefix = append(revs.prefix, line)
+		}
+	}
+	return revs, sc.Err()
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) && (s[i] < 'a' || s[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
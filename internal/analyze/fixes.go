@@ -0,0 +1,74 @@
+package analyze
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FixRules classify commits as bug fixes by their subject line. A subject
+// with a conventional-commit prefix such as "fix(parser)!:" is classified
+// by its type alone, so "docs: fix typo" is not a fix; any other subject
+// is a fix if one of the patterns matches it.
+type FixRules struct {
+	// Types are the conventional-commit types that mark a fix.
+	Types []string
+	// Patterns are matched against subjects without a conventional-commit
+	// prefix.
+	Patterns []*regexp.Regexp
+}
+
+// DefaultFixRules are the rules used when none are configured.
+var DefaultFixRules = FixRules{
+	Types:    []string{"fix", "bugfix", "hotfix"},
+	Patterns: []*regexp.Regexp{regexp.MustCompile(`(?i)\b(fix(e[sd]|ing)?|bug(s|fix)?|hotfix|defect|regression)\b`)},
+}
+
+// conventionalPrefix matches "type(scope)!:" at the start of a subject.
+var conventionalPrefix = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?!?:`)
+
+// CompileFixPatterns compiles patterns for FixRules. Patterns match case
+// insensitively.
+func CompileFixPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fix pattern %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// IsFix reports whether a commit with the given subject fixes a bug.
+func (r FixRules) IsFix(subject string) bool {
+	if m := conventionalPrefix.FindStringSubmatch(subject); m != nil {
+		for _, t := range r.Types {
+			if strings.EqualFold(t, m[1]) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range r.Patterns {
+		if p.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClassifyFixes sets Fix on every commit according to r. Zero rules use
+// DefaultFixRules.
+func (h *History) ClassifyFixes(r FixRules) {
+	if h == nil {
+		return
+	}
+	if r.Types == nil && r.Patterns == nil {
+		r = DefaultFixRules
+	}
+	for i := range h.Commits {
+		h.Commits[i].Fix = r.IsFix(h.Commits[i].Subject)
+	}
+}
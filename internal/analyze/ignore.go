@@ -0,0 +1,216 @@
+package analyze
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFileName is noisemap's own ignore file. It uses .gitignore syntax,
+// may appear in any directory, and is read after the .gitignore in the same
+// directory so it can override it.
+const IgnoreFileName = ".noisemapignore"
+
+// ignoreRule is a single pattern from an ignore file.
+type ignoreRule struct {
+	base     string // directory of the ignore file, relative to the anchor
+	pattern  string // doublestar pattern
+	negate   bool   // "!pattern" re-includes
+	dirOnly  bool   // "pattern/" only matches directories
+	anchored bool   // pattern contains a slash, so it matches from base
+}
+
+// ignoreMatcher evaluates gitignore-style rules. Paths are slash-separated
+// and relative to the anchor: the repository root when scanning inside a
+// git repository, otherwise the scan root.
+type ignoreMatcher struct {
+	anchor    string
+	gitignore bool
+	rules     map[string][]ignoreRule // keyed by directory
+	loaded    map[string]bool
+}
+
+// newIgnoreMatcher prepares a matcher for a walk of root. With gitignore
+// set it reads .git/info/exclude and every .gitignore between the
+// repository root and root; .noisemapignore files are always read.
+func newIgnoreMatcher(root string, gitignore bool) *ignoreMatcher {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	m := &ignoreMatcher{
+		anchor:    root,
+		gitignore: gitignore,
+		rules:     make(map[string][]ignoreRule),
+		loaded:    make(map[string]bool),
+	}
+
+	repo := findRepoRoot(root)
+	if repo == "" {
+		return m
+	}
+	m.anchor = repo
+
+	if gitignore {
+		m.rules[""] = append(m.rules[""],
+			readIgnoreFile(filepath.Join(repo, ".git", "info", "exclude"), "")...)
+	}
+
+	// Ignore files in the directories above root still apply to it.
+	rel := m.relPath(root)
+	if rel == "" {
+		return m
+	}
+	dir := ""
+	m.loadDir(repo)
+	for _, part := range strings.Split(rel, "/") {
+		dir = path.Join(dir, part)
+		m.loadDir(filepath.Join(repo, filepath.FromSlash(dir)))
+	}
+	return m
+}
+
+// findRepoRoot returns the nearest ancestor of dir (or dir itself) that
+// contains a .git entry, or "" if there is none.
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadDir reads the ignore files of the directory at absPath. Each
+// directory is read once.
+func (m *ignoreMatcher) loadDir(absPath string) {
+	rel := m.relPath(absPath)
+	if m.loaded[rel] {
+		return
+	}
+	m.loaded[rel] = true
+
+	var rules []ignoreRule
+	if m.gitignore {
+		rules = append(rules, readIgnoreFile(filepath.Join(absPath, ".gitignore"), rel)...)
+	}
+	rules = append(rules, readIgnoreFile(filepath.Join(absPath, IgnoreFileName), rel)...)
+	m.rules[rel] = append(m.rules[rel], rules...)
+}
+
+// relPath converts an absolute path to the matcher's anchor-relative form.
+func (m *ignoreMatcher) relPath(absPath string) string {
+	rel, err := filepath.Rel(m.anchor, absPath)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ignored reports whether the entry at absPath is ignored. Rules from
+// deeper directories take precedence, and within a file the last matching
+// rule wins, as in git.
+func (m *ignoreMatcher) ignored(absPath string, isDir bool) bool {
+	rel := m.relPath(absPath)
+	if rel == "" {
+		return false
+	}
+
+	dirs := []string{""}
+	for i, c := range rel {
+		if c == '/' {
+			dirs = append(dirs, rel[:i])
+		}
+	}
+
+	ignored := false
+	for _, dir := range dirs {
+		for _, r := range m.rules[dir] {
+			if r.matches(rel, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// matches reports whether the rule applies to rel.
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = rel[len(r.base)+1:]
+	}
+	if !r.anchored {
+		rel = path.Base(rel)
+	}
+	ok, _ := doublestar.Match(r.pattern, rel)
+	return ok
+}
+
+// readIgnoreFile parses a gitignore-syntax file. A missing file yields no
+// rules.
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if r, ok := parseIgnoreLine(sc.Text(), base); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine converts one gitignore line into a rule.
+func parseIgnoreLine(line, base string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped with a backslash.
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	r := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// Braces are literal in gitignore but special in doublestar.
+	line = strings.NewReplacer("{", `\{`, "}", `\}`).Replace(line)
+	r.pattern = line
+	return r, true
+}
@@ -0,0 +1,87 @@
+package analyze
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestIgnoreMatcher checks the matcher against what git check-ignore
+// reports for the same tree.
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".git/info/exclude", "secret.go\n")
+	write(".gitignore", strings.Join([]string{
+		"*.gen.go",
+		"!keep.gen.go",
+		"/root_only.go",
+		"build/",
+		"!build/b.go",
+		"docs/*.go",
+		"logs",
+	}, "\n")+"\n")
+	write("sub/.gitignore", "!*.gen.go\nlocal.go\n")
+
+	tests := []struct {
+		name    string
+		path    string
+		ignored bool
+	}{
+		{"not matched", "a.go", false},
+		{"glob", "x.gen.go", true},
+		{"negation", "keep.gen.go", false},
+		{"nested negation wins", "sub/y.gen.go", false},
+		{"nested rule", "sub/local.go", true},
+		{"nested rule stays in its directory", "local.go", false},
+		{"anchored", "root_only.go", true},
+		{"anchored elsewhere", "sub/root_only.go", false},
+		{"dir-only", "build/b.go", true},
+		{"dir-only at any depth", "sub/build/c.go", true},
+		{"dir-only skips files", "other/build", false},
+		{"pattern with a slash is anchored", "docs/d.go", true},
+		{"star stops at a slash", "docs/api/e.go", false},
+		{"bare name matches a directory", "logs/f.go", true},
+		{"info/exclude", "secret.go", true},
+		{"info/exclude at any depth", "sub/secret.go", true},
+	}
+	for _, tt := range tests {
+		write(tt.path, "")
+	}
+
+	m := newIgnoreMatcher(root, true)
+	m.loadDir(root)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Walk skips ignored directories and loads the ignore files of
+			// the others on its way down.
+			got := false
+			dir := root
+			parts := strings.Split(tt.path, "/")
+			for _, part := range parts[:len(parts)-1] {
+				dir = filepath.Join(dir, part)
+				if m.ignored(dir, true) {
+					got = true
+					break
+				}
+				m.loadDir(dir)
+			}
+			if !got {
+				got = m.ignored(filepath.Join(root, filepath.FromSlash(tt.path)), false)
+			}
+			if got != tt.ignored {
+				t.Errorf("ignored(%s) = %v, want %v", tt.path, got, tt.ignored)
+			}
+		})
+	}
+}
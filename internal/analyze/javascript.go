@@ -0,0 +1,891 @@
+package analyze
+
+import (
+	"os"
+	"strings"
+)
+
+// jsToken is a single JavaScript or TypeScript token. String literals and
+// the literal parts of template strings become "STR", numbers "NUM" and
+// regular expressions "REGEX". Template substitutions are emitted between
+// "(" and ")" so that their expressions stay grouped.
+type jsToken struct {
+	text string
+	line int
+}
+
+// analyzeJS computes per-function complexity for JavaScript and
+// TypeScript source. Function declarations and expressions, arrow
+// functions and class and object methods are each reported on their own,
+// named after their enclosing classes and functions, e.g. "Store.load" or
+// "render.onClick". Functions without a name are reported as
+// "<anonymous>". Code outside any function counts towards the file total
+// only.
+func analyzeJS(path string) ComplexityResult {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return ComplexityResult{Total: 1}
+	}
+
+	p := &jsParser{toks: tokenizeJS(string(src)), module: &jsFunc{cyclomatic: 1}}
+	p.parse()
+
+	total := p.module.cyclomatic
+	cognitive := p.module.cognitive
+	funcs := make([]FuncComplexity, 0, len(p.funcs))
+	for _, fn := range p.funcs {
+		total += fn.cyclomatic - 1
+		cognitive += fn.cognitive
+		funcs = append(funcs, FuncComplexity{
+			Name:       fn.qualified,
+			Complexity: fn.cyclomatic,
+			Cognitive:  fn.cognitive,
+			Line:       fn.line,
+		})
+	}
+	sortFunctions(funcs)
+	return ComplexityResult{Total: total, Cognitive: cognitive, Functions: funcs, Lines: countLines(src)}
+}
+
+// jsFunc accumulates the complexity of one function, or of the module.
+type jsFunc struct {
+	name       string // bare name, for spotting recursion
+	qualified  string
+	line       int
+	method     bool
+	cyclomatic int
+	cognitive  int
+}
+
+// Kinds of jsFrame.
+const (
+	jsBlock  = iota // statement block, or a ( or [ group
+	jsBody          // function body
+	jsClass         // class body
+	jsObject        // object literal or type literal
+)
+
+// jsFrame is an open bracket.
+type jsFrame struct {
+	open  string // "(", "[" or "{"
+	kind  int
+	nests bool   // raises the nesting level for cognitive complexity
+	qual  string // qualifier for functions declared inside, if it changes here
+	start int    // token index of the bracket
+
+	header bool    // ( of an if, for, while, switch or catch
+	params *jsFunc // ( of a function or method parameter list
+	typ    bool    // holds TypeScript types, e.g. an interface body
+}
+
+// jsTypeCtx is a TypeScript type being skipped outside any type frame: the
+// right-hand side of a type alias or a ": Type" annotation. Function types
+// in it, "(x: T) => U", are not functions.
+type jsTypeCtx struct {
+	depth      int    // frame depth it started at
+	angle      int    // open < at that depth
+	alias      bool   // "type X = ..."; otherwise an annotation
+	returnType bool   // "(...): Type =>"; ends at the arrow
+	name       string // the annotated name, "x" in "x: T"
+}
+
+// jsScope is a function being parsed. Its body starts at frame depth.
+// Expression-bodied arrow functions have no frame of their own and end
+// at the first , or ; at their depth, or when that depth is closed.
+type jsScope struct {
+	fn    *jsFunc
+	depth int
+	expr  bool
+}
+
+type jsParser struct {
+	toks   []jsToken
+	module *jsFunc
+	funcs  []*jsFunc
+
+	frames []jsFrame
+	scopes []jsScope
+	openAt map[int]int // index of each ")" to its "("
+
+	// State carried from one token to the next.
+	nextBlockNests bool    // the next "{" is the body of a control statement
+	afterDo        bool    // the last "}" closed a do block
+	pendingBody    *jsFunc // a parameter list closed; a "{" may start the body
+	bodyDepth      int     // frame depth of that "{"
+	inReturnType   bool    // skipping a ": Type" annotation before the body
+	angle          int     // <> depth inside the return type
+	classPending   bool    // a class keyword was seen; "{" starts its body
+	className      string
+	lastBool       string // last &&, || or ?? in the current expression
+
+	typ              *jsTypeCtx // the type being skipped, if any
+	interfacePending bool       // an interface keyword was seen; "{" starts its body
+	ternaries        []int      // frame depth of each ? awaiting its :
+	annotated        string     // name before the type ending at the "=" at token assignAt
+	assignAt         int
+}
+
+var jsKeywords = map[string]bool{
+	"if": true, "else": true, "for": true, "while": true, "do": true,
+	"switch": true, "case": true, "default": true, "catch": true, "try": true,
+	"finally": true, "function": true, "return": true, "typeof": true,
+	"new": true, "delete": true, "void": true, "throw": true, "in": true,
+	"of": true, "instanceof": true, "yield": true, "await": true,
+	"class": true, "super": true, "import": true, "export": true,
+	"const": true, "let": true, "var": true, "break": true, "continue": true,
+}
+
+// jsStatementStart are keywords that end an expression-bodied arrow
+// function when they start a new line.
+var jsStatementStart = map[string]bool{
+	"const": true, "let": true, "var": true, "function": true, "class": true,
+	"export": true, "import": true, "return": true, "if": true, "for": true,
+	"while": true, "switch": true, "try": true, "throw": true, "async": true,
+	"abstract": true, "interface": true, "type": true, "enum": true,
+	"declare": true, "namespace": true, "@": true,
+}
+
+// jsTypeContinues reports whether a type that reached the end of a line
+// after prev goes on with t, the first token of the next line.
+func jsTypeContinues(prev, t string) bool {
+	switch prev {
+	case "=", "|", "&", "=>", ",", ":", "?", "<", ".", "extends", "keyof", "typeof":
+		return true
+	}
+	switch t {
+	case "|", "&", "?", ":", ".", "=", "=>", "extends":
+		return true
+	}
+	return false
+}
+
+// jsEndsType are the tokens that end a return type annotation when they
+// appear outside any bracket within it.
+var jsEndsType = map[string]bool{";": true, "=": true, "=>": true, ",": true}
+
+func (p *jsParser) parse() {
+	p.openAt = make(map[int]int)
+	for i := range p.toks {
+		p.token(i)
+	}
+}
+
+func (p *jsParser) text(i int) string {
+	if i < 0 || i >= len(p.toks) {
+		return ""
+	}
+	return p.toks[i].text
+}
+
+// current returns the innermost function, or the module.
+func (p *jsParser) current() *jsFunc {
+	if len(p.scopes) == 0 {
+		return p.module
+	}
+	return p.scopes[len(p.scopes)-1].fn
+}
+
+// nesting returns the cognitive nesting level inside the current function.
+func (p *jsParser) nesting() int {
+	base := 0
+	if len(p.scopes) > 0 {
+		base = p.scopes[len(p.scopes)-1].depth
+	}
+	n := 0
+	for _, f := range p.frames[base:] {
+		if f.nests {
+			n++
+		}
+	}
+	return n
+}
+
+// qualifier returns the name new functions are qualified with.
+func (p *jsParser) qualifier() string {
+	k := -1
+	for i := len(p.frames) - 1; i >= 0; i-- {
+		if p.frames[i].qual != "" {
+			k = i
+			break
+		}
+	}
+	if len(p.scopes) > 0 {
+		if s := p.scopes[len(p.scopes)-1]; s.depth > k {
+			return s.fn.qualified
+		}
+	}
+	if k < 0 {
+		return ""
+	}
+	return p.frames[k].qual
+}
+
+// newFunc prepares a function named name (or anonymous) found at token i.
+// It is only reported once its body is found.
+func (p *jsParser) newFunc(name string, i int, method bool) *jsFunc {
+	if name == "" {
+		name = "<anonymous>"
+	}
+	qualified := name
+	if q := p.qualifier(); q != "" {
+		qualified = q + "." + name
+	}
+	line := 1
+	if i >= 0 && i < len(p.toks) {
+		line = p.toks[i].line
+	}
+	return &jsFunc{name: name, qualified: qualified, line: line, method: method, cyclomatic: 1}
+}
+
+// enter starts the body of fn at the current depth.
+func (p *jsParser) enter(fn *jsFunc, expr bool) {
+	p.funcs = append(p.funcs, fn)
+	p.scopes = append(p.scopes, jsScope{fn: fn, depth: len(p.frames), expr: expr})
+	p.lastBool = ""
+}
+
+// closeScopes ends the functions whose bodies are no longer open.
+func (p *jsParser) closeScopes() {
+	for len(p.scopes) > 0 && p.scopes[len(p.scopes)-1].depth > len(p.frames) {
+		p.scopes = p.scopes[:len(p.scopes)-1]
+	}
+}
+
+// endExprScopes ends expression-bodied arrows at the current depth.
+func (p *jsParser) endExprScopes() {
+	for len(p.scopes) > 0 {
+		s := p.scopes[len(p.scopes)-1]
+		if !s.expr || s.depth != len(p.frames) {
+			return
+		}
+		p.scopes = p.scopes[:len(p.scopes)-1]
+	}
+}
+
+// awaitBody records that a "{" at the current depth would open fn's body.
+func (p *jsParser) awaitBody(fn *jsFunc) {
+	p.pendingBody = fn
+	p.bodyDepth = len(p.frames)
+	p.inReturnType = false
+	p.angle = 0
+}
+
+// skipReturnType consumes the tokens between a parameter list and the
+// function body: nothing, or a ": Type" annotation. It reports whether
+// token i was consumed; otherwise the pending body is abandoned (an
+// overload signature, a call, ...) unless token i opens it.
+func (p *jsParser) skipReturnType(i int) bool {
+	t := p.toks[i].text
+	nested := len(p.frames) > p.bodyDepth
+	switch {
+	case t == "{" && p.angle == 0 && !nested:
+		return false // the body; handled by the caller
+	case p.inReturnType && !nested && p.angle == 0 && p.newLine(i) && !jsTypeContinues(p.text(i-1), t):
+		// A signature without a semicolon: "abstract n(): void".
+	case !p.inReturnType && t == ":":
+		p.inReturnType = true
+		return true
+	case p.inReturnType:
+		closer := t == ")" || t == "]" || t == "}"
+		if nested || (!closer && (p.angle > 0 || !jsEndsType[t])) {
+			switch t {
+			case "<":
+				p.angle++
+			case ">":
+				p.angle--
+			case "(", "[", "{":
+				p.push(i, jsFrame{open: t, kind: jsObject})
+			case ")", "]", "}":
+				p.pop(i)
+			}
+			return true
+		}
+	}
+	p.pendingBody = nil
+	p.inReturnType = false
+	return false
+}
+
+func (p *jsParser) token(i int) {
+	t := p.toks[i].text
+	prev := p.text(i - 1)
+
+	// An expression-bodied arrow also ends at a statement keyword that
+	// starts a new line, and so does a type without a semicolon.
+	if p.newLine(i) {
+		if jsStatementStart[t] {
+			p.endExprScopes()
+		}
+		if c := p.typ; c != nil && c.depth == len(p.frames) && c.angle == 0 && !jsTypeContinues(prev, t) {
+			p.typ = nil
+		}
+	}
+
+	nextBlockNests := p.nextBlockNests
+	p.nextBlockNests = false
+	afterDo := p.afterDo
+	p.afterDo = false
+
+	if p.pendingBody != nil && p.skipReturnType(i) {
+		return
+	}
+	// Keywords are plain property names after a dot: promise.catch(...).
+	if (prev == "." || prev == "?.") && isJSIdent(t) {
+		return
+	}
+	p.trackType(i)
+
+	fn := p.current()
+	switch t {
+	case "{":
+		f := jsFrame{open: "{"}
+		switch {
+		case p.pendingBody != nil:
+			body := p.pendingBody
+			p.pendingBody = nil
+			f.kind = jsBody
+			f.qual = body.qualified
+			p.typ = nil
+			p.push(i, f)
+			p.enter(body, false)
+			return
+		case isJSObjectStart(prev) && (p.interfacePending || p.classPending):
+			// A type literal in the heading: "extends Base<{...}>".
+			f.kind = jsObject
+			f.typ = true
+		case p.interfacePending:
+			f.kind = jsObject
+			f.typ = true
+			p.interfacePending = false
+		case p.classPending:
+			f.kind = jsClass
+			f.qual = p.className
+			if q := p.qualifier(); q != "" && f.qual != "" {
+				f.qual = q + "." + f.qual
+			}
+			p.classPending = false
+		case nextBlockNests:
+			f.nests = true
+		case isJSObjectStart(prev):
+			f.kind = jsObject
+		}
+		p.push(i, f)
+		p.lastBool = ""
+
+	case "(":
+		f := jsFrame{open: t}
+		k := p.skipTypeArgs(i - 1) // name<T>(...)
+		switch name := p.text(k); {
+		case prev == "if" || prev == "for" || prev == "while" || prev == "switch" || prev == "catch" ||
+			(prev == "await" && p.text(i-2) == "for"):
+			f.header = true
+		case name == "function" || (name == "*" && p.text(k-1) == "function"):
+			f.params = p.newFunc(p.assignedName(p.beforeFunction(k)), i, false)
+		case isJSIdent(name) && (p.text(k-1) == "function" || (p.text(k-1) == "*" && p.text(k-2) == "function")):
+			f.params = p.newFunc(name, i, false)
+		case p.inMemberList() && !p.inType() && p.text(k-1) != "." && (isJSIdent(name) || name == "]" || name == "STR"):
+			if !isJSIdent(name) {
+				name = ""
+			}
+			f.params = p.newFunc(name, k, true)
+		case prev == fn.name && p.isRecursiveCall(fn, i):
+			fn.cognitive++
+		}
+		p.push(i, f)
+		p.lastBool = ""
+
+	case "[":
+		p.push(i, jsFrame{open: t})
+
+	case ")", "]":
+		p.endExprScopes()
+		f, ok := p.pop(i)
+		switch {
+		case !ok:
+		case f.header:
+			p.nextBlockNests = true
+		case f.params != nil:
+			p.awaitBody(f.params)
+		}
+
+	case "}":
+		p.endExprScopes()
+		if f, ok := p.pop(i); ok && f.nests && p.text(f.start-1) == "do" {
+			p.afterDo = true
+		}
+		p.lastBool = ""
+
+	case "=>":
+		if p.inType() {
+			break // a function type
+		}
+		name, start := p.arrowName(i)
+		arrow := p.newFunc(name, start, false)
+		if p.text(i+1) == "{" {
+			p.awaitBody(arrow)
+		} else {
+			p.enter(arrow, true)
+		}
+
+	case ",", ";":
+		p.endExprScopes()
+		p.lastBool = ""
+
+	case "class":
+		if prev != "." {
+			p.classPending = true
+			p.className = p.assignedName(i - 1)
+			if n := p.text(i + 1); isJSIdent(n) && !jsKeywords[n] && n != "extends" && n != "implements" {
+				p.className = n
+			}
+		}
+
+	case "if":
+		fn.cyclomatic++
+		if prev == "else" {
+			fn.cognitive++
+		} else {
+			fn.cognitive += 1 + p.nesting()
+		}
+	case "else":
+		if p.text(i+1) != "if" {
+			fn.cognitive++
+			p.nextBlockNests = true
+		}
+	case "for":
+		fn.cyclomatic++
+		fn.cognitive += 1 + p.nesting()
+	case "catch":
+		fn.cyclomatic++
+		fn.cognitive += 1 + p.nesting()
+		p.nextBlockNests = p.text(i+1) == "{" // catch without a binding
+	case "while":
+		if !afterDo {
+			fn.cyclomatic++
+			fn.cognitive += 1 + p.nesting()
+		}
+	case "do":
+		fn.cyclomatic++
+		fn.cognitive += 1 + p.nesting()
+		p.nextBlockNests = true
+	case "switch":
+		fn.cognitive += 1 + p.nesting()
+	case "case":
+		fn.cyclomatic++
+	case "?":
+		// TypeScript optional members and parameters: "x?: T", "x?)".
+		switch p.text(i + 1) {
+		case ":", ",", ")", "=", ";":
+		default:
+			if !p.inType() {
+				fn.cyclomatic++
+				fn.cognitive += 1 + p.nesting()
+				p.ternaries = append(p.ternaries, len(p.frames))
+			}
+		}
+		p.lastBool = ""
+	case "?.", "&&=", "||=", "??=":
+		fn.cyclomatic++
+	case "&&", "||", "??":
+		fn.cyclomatic++
+		if t != p.lastBool {
+			fn.cognitive++
+		}
+		p.lastBool = t
+	case "=", ":", "return":
+		p.lastBool = ""
+	case "break", "continue":
+		if n := p.text(i + 1); isJSIdent(n) && !jsKeywords[n] && p.toks[i+1].line == p.toks[i].line {
+			fn.cognitive++
+		}
+	}
+}
+
+// trackType starts and ends the TypeScript type being skipped at token i.
+func (p *jsParser) trackType(i int) {
+	t, prev := p.toks[i].text, p.text(i-1)
+	if c := p.typ; c != nil {
+		switch {
+		case len(p.frames) != c.depth:
+		case t == "<":
+			c.angle++
+		case t == ">" && c.angle > 0:
+			c.angle--
+		case c.angle > 0:
+		case t == "{" && !isJSObjectStart(prev) && prev != "=>":
+			p.typ = nil // a body after the return type of a method
+		case t == "=" && !c.alias:
+			p.annotated, p.assignAt = c.name, i
+			p.typ = nil
+		case t == ";", t == "=>" && c.returnType, t == "," && !c.alias:
+			p.typ = nil
+		}
+		return
+	}
+	if p.inType() {
+		return
+	}
+	switch t {
+	case "type":
+		if isJSIdent(p.text(i+1)) && (p.text(i+2) == "=" || p.text(i+2) == "<") {
+			p.typ = &jsTypeCtx{depth: len(p.frames), alias: true}
+		}
+	case "interface":
+		p.interfacePending = isJSIdent(p.text(i + 1))
+	case ":":
+		if n := len(p.ternaries); n > 0 && p.ternaries[n-1] == len(p.frames) {
+			p.ternaries = p.ternaries[:n-1]
+			return
+		}
+		var top jsFrame
+		if len(p.frames) > 0 {
+			top = p.frames[len(p.frames)-1]
+		}
+		switch {
+		case prev == ")":
+			// "(...): Type =>", but not "case (x):".
+			if p.text(p.openAt[i-1]-1) != "case" {
+				p.typ = &jsTypeCtx{depth: len(p.frames), returnType: true}
+			}
+		case top.open == "(",
+			top.kind == jsClass && (isJSIdent(prev) || prev == "?" || prev == "]" || prev == "!"),
+			isJSIdent(prev) && (p.text(i-2) == "const" || p.text(i-2) == "let" || p.text(i-2) == "var"):
+			p.typ = &jsTypeCtx{depth: len(p.frames), name: prev}
+		}
+	}
+}
+
+// inType reports whether the parser is inside a TypeScript type.
+func (p *jsParser) inType() bool {
+	return p.typ != nil || (len(p.frames) > 0 && p.frames[len(p.frames)-1].typ)
+}
+
+// newLine reports whether token i starts a line.
+func (p *jsParser) newLine(i int) bool {
+	return i > 0 && p.toks[i-1].line != p.toks[i].line
+}
+
+func (p *jsParser) push(i int, f jsFrame) {
+	f.start = i
+	if f.kind != jsBody && p.inType() {
+		f.typ = true
+	}
+	p.frames = append(p.frames, f)
+}
+
+// pop closes the innermost frame at the closing bracket at token i. A
+// stray closing bracket is ignored.
+func (p *jsParser) pop(i int) (jsFrame, bool) {
+	if len(p.frames) == 0 {
+		return jsFrame{}, false
+	}
+	f := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	p.openAt[i] = f.start
+	p.closeScopes()
+	if p.typ != nil && p.typ.depth > len(p.frames) {
+		p.typ = nil
+	}
+	for n := len(p.ternaries); n > 0 && p.ternaries[n-1] > len(p.frames); n-- {
+		p.ternaries = p.ternaries[:n-1]
+	}
+	return f, true
+}
+
+// inMemberList reports whether the innermost frame is a class body or an
+// object literal, where "name(" may start a method.
+func (p *jsParser) inMemberList() bool {
+	if len(p.frames) == 0 {
+		return false
+	}
+	k := p.frames[len(p.frames)-1].kind
+	return k == jsClass || k == jsObject
+}
+
+// skipTypeArgs steps back over a type argument list ending at token j,
+// returning the index of the token before it, or j if there is none.
+func (p *jsParser) skipTypeArgs(j int) int {
+	if p.text(j) != ">" {
+		return j
+	}
+	for k, depth := j, 0; k >= 0; k-- {
+		switch p.text(k) {
+		case ">":
+			depth++
+		case "<":
+			if depth--; depth == 0 {
+				return k - 1
+			}
+		case ";", "{", "}":
+			return j
+		}
+	}
+	return j
+}
+
+// beforeFunction returns the index of the token before the "function"
+// keyword ending at token k (which is "function" or the "*" after it),
+// skipping an "async" modifier.
+func (p *jsParser) beforeFunction(k int) int {
+	if p.text(k) == "*" {
+		k--
+	}
+	k--
+	if p.text(k) == "async" {
+		k--
+	}
+	return k
+}
+
+// arrowName names the arrow function at token i (the "=>") and returns the
+// index where its parameters start.
+func (p *jsParser) arrowName(i int) (string, int) {
+	start := i - 1
+	if start < 0 {
+		return "", i
+	}
+	if p.text(start) == ")" {
+		start = p.openAt[start]
+	} else {
+		// A return type annotation: "(...): Type =>".
+		for k := i - 1; k > 0 && i-k < 64; k-- {
+			t := p.text(k)
+			if t == ")" && p.text(k+1) == ":" {
+				start = p.openAt[k]
+				break
+			}
+			if !isJSTypeToken(t) {
+				break
+			}
+		}
+	}
+	j := p.skipTypeArgs(start - 1)
+	if p.text(j) == "async" {
+		j--
+	}
+	return p.assignedName(j), start
+}
+
+// assignedName returns the name in "name =", "name: Type =" or "name:"
+// ending at token j.
+func (p *jsParser) assignedName(j int) string {
+	if j == p.assignAt && p.annotated != "" && isJSIdent(p.annotated) {
+		return p.annotated
+	}
+	if (p.text(j) == "=" || p.text(j) == ":") && isJSIdent(p.text(j-1)) && !jsKeywords[p.text(j-1)] {
+		return p.text(j - 1)
+	}
+	return ""
+}
+
+// isRecursiveCall reports whether the "(" at token i calls fn itself.
+func (p *jsParser) isRecursiveCall(fn *jsFunc, i int) bool {
+	if fn.name == "" {
+		return false
+	}
+	if fn.method {
+		return p.text(i-2) == "." && p.text(i-3) == "this"
+	}
+	return p.text(i-2) != "." && p.text(i-2) != "function"
+}
+
+// isJSTypeToken reports whether t may appear in a simple type annotation.
+func isJSTypeToken(t string) bool {
+	switch t {
+	case ".", "<", ">", "[", "]", "|", "&", ",", ":", "STR", "NUM":
+		return true
+	}
+	return isJSIdent(t)
+}
+
+// isJSObjectStart reports whether a "{" after prev opens an object literal
+// (or, in TypeScript, a type literal) rather than a block.
+func isJSObjectStart(prev string) bool {
+	switch prev {
+	case "=", "(", ",", ":", "[", "?", "||", "&&", "??", "return", "...", "<", "|", "&":
+		return true
+	}
+	return false
+}
+
+func isJSIdent(s string) bool {
+	if s == "" || s == "STR" || s == "NUM" || s == "REGEX" {
+		return false
+	}
+	c := s[0]
+	return c == '_' || c == '$' || c == '#' || c >= 0x80 || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+func isJSIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+// jsRegexPrefix are keywords after which "/" starts a regular expression
+// rather than a division.
+var jsRegexPrefix = map[string]bool{
+	"return": true, "typeof": true, "case": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "else": true,
+	"do": true, "instanceof": true, "yield": true, "await": true,
+}
+
+// jsPunctuators are the multi-character operators the analyzer cares
+// about, longest first. Everything else is emitted one byte at a time.
+var jsPunctuators = []string{"??=", "&&=", "||=", "...", "?.", "??", "&&", "||", "=>"}
+
+// tokenizeJS splits JavaScript or TypeScript source into tokens, dropping
+// comments and the contents of literals.
+func tokenizeJS(src string) []jsToken {
+	var toks []jsToken
+	line := 1
+	// Brace depth inside each open template substitution.
+	var templates []int
+
+	add := func(text string) { toks = append(toks, jsToken{text: text, line: line}) }
+	prevText := func() string {
+		if len(toks) == 0 {
+			return ""
+		}
+		return toks[len(toks)-1].text
+	}
+
+	i := 0
+	if strings.HasPrefix(src, "#!") {
+		for i < len(src) && src[i] != '\n' {
+			i++
+		}
+	}
+
+	// scanTemplate consumes template text from i (just past a "`" or a
+	// substitution's closing brace) up to the closing "`" or the next "${".
+	scanTemplate := func() {
+		add("STR")
+		for i < len(src) {
+			switch c := src[i]; {
+			case c == '\\':
+				i += 2
+				continue
+			case c == '\n':
+				line++
+			case c == '`':
+				i++
+				return
+			case c == '$' && i+1 < len(src) && src[i+1] == '{':
+				i += 2
+				templates = append(templates, 0)
+				add("(")
+				return
+			}
+			i++
+		}
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+		case c == '\'' || c == '"':
+			for i++; i < len(src) && src[i] != c && src[i] != '\n'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			if i < len(src) && src[i] == c {
+				i++
+			}
+			add("STR")
+		case c == '`':
+			i++
+			scanTemplate()
+		case c == '{':
+			if len(templates) > 0 {
+				templates[len(templates)-1]++
+			}
+			add("{")
+			i++
+		case c == '}':
+			i++
+			if n := len(templates); n > 0 {
+				if templates[n-1] == 0 {
+					templates = templates[:n-1]
+					add(")")
+					scanTemplate()
+					continue
+				}
+				templates[n-1]--
+			}
+			add("}")
+		case c == '/' && !strings.HasPrefix(src[i:], "/>") && jsRegexAllowed(prevText()):
+			inClass := false
+			for i++; i < len(src) && src[i] != '\n'; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '[' {
+					inClass = true
+				} else if src[i] == ']' {
+					inClass = false
+				} else if src[i] == '/' && !inClass {
+					break
+				}
+			}
+			for i++; i < len(src) && isJSIdentByte(src[i]); i++ {
+			}
+			add("REGEX")
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(src[i+1])):
+			for i < len(src) && (isJSIdentByte(src[i]) || src[i] == '.') {
+				i++
+			}
+			add("NUM")
+		case isJSIdentByte(c) || (c == '#' && i+1 < len(src) && isJSIdentByte(src[i+1])):
+			j := i + 1
+			for j < len(src) && isJSIdentByte(src[j]) {
+				j++
+			}
+			add(src[i:j])
+			i = j
+		default:
+			op := string(c)
+			for _, p := range jsPunctuators {
+				if strings.HasPrefix(src[i:], p) {
+					op = p
+					break
+				}
+			}
+			// "a?.5:b" is a conditional, not optional chaining.
+			if op == "?." && i+2 < len(src) && isDigit(src[i+2]) {
+				op = "?"
+			}
+			add(op)
+			i += len(op)
+		}
+	}
+	return toks
+}
+
+// jsRegexAllowed reports whether a "/" after prev starts a regular
+// expression.
+func jsRegexAllowed(prev string) bool {
+	switch prev {
+	case "", "(", ",", "=", ":", "[", "!", "&", "|", "?", "{", ";", "&&", "||", "??", "=>", "+", "-", "*", "%", "~", "^", "...":
+		return true
+	case ")", "]", "}", "STR", "NUM", "REGEX":
+		// "}" usually ends an object literal or, in JSX, an attribute
+		// before "/>".
+		return false
+	}
+	return jsRegexPrefix[prev]
+}
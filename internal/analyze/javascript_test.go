@@ -0,0 +1,182 @@
+package analyze
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// analyzeSource runs analyze on src saved under name and returns its
+// functions as "name cyclomatic/cognitive", in source order.
+func analyzeSource(t *testing.T, analyze func(string) ComplexityResult, name, src string) []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	funcs := analyze(path).Functions
+	sort.SliceStable(funcs, func(i, j int) bool { return funcs[i].Line < funcs[j].Line })
+	got := make([]string, len(funcs))
+	for i, fn := range funcs {
+		got[i] = fmt.Sprintf("%s %d/%d", fn.Name, fn.Complexity, fn.Cognitive)
+	}
+	return got
+}
+
+func TestAnalyzeJS(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		src  string
+		want []string
+	}{
+		{
+			name: "regex after (",
+			file: "a.js",
+			src: `function f(s) {
+  if (s) { s = s.split(/[{}(]/) }
+  return s
+}
+function g(a) { return a || 0 }
+`,
+			want: []string{"f 2/1", "g 2/1"},
+		},
+		{
+			name: "regex after = and return",
+			file: "a.js",
+			src: `const re = /{+/g
+function f(s) {
+  return /}/.test(s) ? 1 : 2
+}
+function g(a) { if (a) {} }
+`,
+			want: []string{"f 2/1", "g 2/1"},
+		},
+		{
+			name: "division is not a regex",
+			file: "a.js",
+			src: `function f(a, b) { return (a) / b / 2 }
+function g(a) { if (a) {} }
+`,
+			want: []string{"f 1/0", "g 2/1"},
+		},
+		{
+			name: "nested template substitutions",
+			file: "a.js",
+			src:  "function f(a) {\n  return `x${a ? `{${a}}` : \"}\"}y`\n}\nfunction g(b) { if (b) {} }\n",
+			want: []string{"f 2/1", "g 2/1"},
+		},
+		{
+			name: "arrow functions named after their variable",
+			file: "a.js",
+			src: `const f = () => 1
+const g = async (x) => {
+  if (x) {}
+}
+let h = x => x && x.y
+`,
+			want: []string{"f 1/0", "g 2/1", "h 2/1"},
+		},
+		{
+			name: "object property arrows and methods",
+			file: "a.js",
+			src: `const handlers = {
+  onClick: (e) => e && e.x,
+  load() { return this.x ? 1 : 2 },
+  save: function () {},
+}
+`,
+			want: []string{"onClick 2/1", "load 2/1", "save 1/0"},
+		},
+		{
+			name: "class methods and getters",
+			file: "a.js",
+			src: `class Store {
+  get size() { return this.n ? 1 : 0 }
+  load(x) {
+    if (x) {
+      const cb = () => x
+    }
+  }
+  static create() {}
+}
+`,
+			want: []string{"Store.size 2/1", "Store.load 2/1", "Store.load.cb 1/0", "Store.create 1/0"},
+		},
+		{
+			name: "type alias function types",
+			file: "a.ts",
+			src: `type Handler = (req: Request) => Promise<void>
+async function main(a: string) {
+  if (a) {}
+}
+type Cb = () => void
+`,
+			want: []string{"main 2/1"},
+		},
+		{
+			name: "interface members",
+			file: "a.ts",
+			src: `interface Props {
+  onChange: (v: string) => void;
+  render(x: number): string
+}
+function f(p: Props) { return p ? 1 : 2 }
+`,
+			want: []string{"f 2/1"},
+		},
+		{
+			name: "annotations",
+			file: "a.ts",
+			src: `function run(cb: (x: number) => void, n = 1) { cb(n) }
+const f: (a: number) => number = (a) => a
+class Button {
+  handler: (e: Event) => void = (e) => { if (e) {} }
+  private listeners?: Map<string, (a: number) => void>
+}
+const g = (x: number): string => String(x)
+const t = ok ? (a) : b
+`,
+			want: []string{"run 1/0", "f 1/0", "Button.handler 2/1", "g 1/0"},
+		},
+		{
+			name: "abstract methods after a type alias",
+			file: "a.ts",
+			src: `type Fn = (a: number) => number
+abstract class C {
+  abstract n(): void
+  m(x: number) { return x ? 1 : 2 }
+}
+`,
+			want: []string{"C.m 2/1"},
+		},
+		{
+			name: "type literal in a class heading",
+			file: "a.ts",
+			src: `class Request extends Emitter<{
+  success: (r: Response) => void;
+}> {
+  url() { return this.u || "" }
+}
+`,
+			want: []string{"Request.url 2/1"},
+		},
+		{
+			name: "leading arrow",
+			file: "a.js",
+			src:  "=> x\n",
+			want: []string{"<anonymous> 1/0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := analyzeSource(t, analyzeJS, tt.file, tt.src)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("functions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
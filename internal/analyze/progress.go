@@ -0,0 +1,59 @@
+package analyze
+
+import (
+	"sync"
+	"time"
+)
+
+// Progress is a snapshot of a running scan, reported through
+// Options.Progress.
+type Progress struct {
+	FilesFound     int
+	ComplexityDone int
+	ChurnDone      int
+	// HistoryLoaded is set once the git history has been read.
+	HistoryLoaded bool
+	// Current is the file most recently analyzed, relative to the root.
+	Current string
+}
+
+// Fraction returns how much of the per-file work has finished, from 0 to 1.
+func (p Progress) Fraction() float64 {
+	if p.FilesFound == 0 {
+		return 0
+	}
+	return float64(p.ComplexityDone+p.ChurnDone) / float64(2*p.FilesFound)
+}
+
+// ETA estimates the time remaining given how long the scan has run so
+// far. It returns 0 until there is enough progress to extrapolate from.
+func (p Progress) ETA(elapsed time.Duration) time.Duration {
+	f := p.Fraction()
+	if f <= 0 || f >= 1 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * (1 - f) / f)
+}
+
+// progressTracker serializes updates from concurrent workers and forwards
+// each new snapshot to the caller's callback.
+type progressTracker struct {
+	mu sync.Mutex
+	p  Progress
+	fn func(Progress)
+}
+
+func newProgressTracker(fn func(Progress)) *progressTracker {
+	return &progressTracker{fn: fn}
+}
+
+// update applies change and reports the resulting snapshot.
+func (t *progressTracker) update(change func(p *Progress)) {
+	if t.fn == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	change(&t.p)
+	t.fn(t.p)
+}
@@ -0,0 +1,401 @@
+package analyze
+
+import (
+	"os"
+	"strings"
+)
+
+// pyToken is a single Python token. Strings of every kind, docstrings
+// included, are collapsed to "STR" and numbers to "NUM" so their contents
+// are never mistaken for keywords.
+type pyToken struct {
+	text  string
+	depth int // bracket depth the token appears at
+}
+
+// pyLine is a logical line: physical lines joined by brackets or a
+// trailing backslash, with comments removed.
+type pyLine struct {
+	num    int // physical line of the first token
+	indent int
+	tokens []pyToken
+}
+
+// analyzePython computes per-function complexity for Python source.
+// Functions are found by indentation: a def (or async def) owns every
+// following line that is indented deeper. Methods and nested functions
+// are named after their enclosing classes and functions, e.g.
+// "Parser.parse" or "outer.inner". Code outside any function counts
+// towards the file total only.
+func analyzePython(path string) ComplexityResult {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return ComplexityResult{Total: 1}
+	}
+
+	type scope struct {
+		indent int
+		name   string
+		fn     *pyFunc // nil for classes
+	}
+
+	module := &pyFunc{}
+	var funcs []*pyFunc
+	var stack []scope
+
+	for _, l := range tokenizePython(string(src)) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= l.indent {
+			stack = stack[:len(stack)-1]
+		}
+		owner := module
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].fn != nil {
+				owner = stack[i].fn
+				break
+			}
+		}
+
+		kind, name := pyDefinition(l)
+		switch kind {
+		case "def":
+			qualified := name
+			for i := len(stack) - 1; i >= 0; i-- {
+				qualified = stack[i].name + "." + qualified
+			}
+			fn := &pyFunc{
+				name:       name,
+				qualified:  qualified,
+				line:       l.num,
+				cyclomatic: 1,
+				method:     len(stack) > 0 && stack[len(stack)-1].fn == nil,
+			}
+			funcs = append(funcs, fn)
+			stack = append(stack, scope{indent: l.indent, name: name, fn: fn})
+			fn.score(l)
+		case "class":
+			stack = append(stack, scope{indent: l.indent, name: name})
+		default:
+			owner.score(l)
+		}
+	}
+
+	total := 1 + module.cyclomatic
+	cognitive := module.cognitive
+	out := make([]FuncComplexity, 0, len(funcs))
+	for _, fn := range funcs {
+		total += fn.cyclomatic - 1
+		cognitive += fn.cognitive
+		out = append(out, FuncComplexity{
+			Name:       fn.qualified,
+			Complexity: fn.cyclomatic,
+			Cognitive:  fn.cognitive,
+			Line:       fn.line,
+		})
+	}
+	sortFunctions(out)
+	return ComplexityResult{Total: total, Cognitive: cognitive, Functions: out, Lines: countLines(src)}
+}
+
+// pyDefinition reports whether l starts a function or class, and its name.
+func pyDefinition(l pyLine) (kind, name string) {
+	toks := l.tokens
+	if len(toks) > 0 && toks[0].text == "async" {
+		toks = toks[1:]
+	}
+	if len(toks) < 2 {
+		return "", ""
+	}
+	switch toks[0].text {
+	case "def", "class":
+		return toks[0].text, toks[1].text
+	}
+	return "", ""
+}
+
+// pyFunc accumulates the complexity of one function, or of the module.
+type pyFunc struct {
+	name       string // bare name, for spotting recursion
+	qualified  string
+	line       int
+	method     bool
+	cyclomatic int
+	cognitive  int
+	blocks     []pyBlock // open control blocks, innermost last
+}
+
+// pyBlock is an open compound statement inside a function.
+type pyBlock struct {
+	indent int
+	nests  bool // whether it raises the nesting level for cognitive complexity
+}
+
+// nesting closes the blocks that a line at indent has left and returns
+// the nesting level of that line.
+func (f *pyFunc) nesting(indent int) int {
+	for len(f.blocks) > 0 && f.blocks[len(f.blocks)-1].indent >= indent {
+		f.blocks = f.blocks[:len(f.blocks)-1]
+	}
+	n := 0
+	for _, b := range f.blocks {
+		if b.nests {
+			n++
+		}
+	}
+	return n
+}
+
+// score adds the decision points of one logical line. Cyclomatic
+// complexity counts if, elif, for, while, except, case, and, or,
+// conditional expressions and comprehension clauses. Cognitive complexity
+// follows the same rules as for Go, with elif and else counted like
+// else if and else, and match like switch.
+func (f *pyFunc) score(l pyLine) {
+	n := f.nesting(l.indent)
+	toks := l.tokens
+	first := 0
+	if len(toks) > 1 && toks[0].text == "async" {
+		first = 1
+	}
+	header := toks[len(toks)-1].text == ":"
+
+	switch toks[first].text {
+	case "if", "while", "for", "except":
+		f.cyclomatic++
+		f.cognitive += 1 + n
+		f.blocks = append(f.blocks, pyBlock{indent: l.indent, nests: true})
+		first++
+	case "elif":
+		f.cyclomatic++
+		f.cognitive++
+		f.blocks = append(f.blocks, pyBlock{indent: l.indent, nests: true})
+		first++
+	case "else":
+		f.cognitive++
+		f.blocks = append(f.blocks, pyBlock{indent: l.indent, nests: true})
+		first++
+	case "try", "finally", "with":
+		f.blocks = append(f.blocks, pyBlock{indent: l.indent})
+	case "match":
+		// match and case are soft keywords; "match = 1" is an assignment.
+		if header && pySoftKeyword(toks, first) {
+			f.cognitive += 1 + n
+			f.blocks = append(f.blocks, pyBlock{indent: l.indent, nests: true})
+			first++
+		}
+	case "case":
+		if header && pySoftKeyword(toks, first) {
+			if !(len(toks)-first == 3 && toks[first+1].text == "_") {
+				f.cyclomatic++
+			}
+			f.blocks = append(f.blocks, pyBlock{indent: l.indent})
+			first++
+		}
+	}
+
+	lastBool := ""
+	comprehension := false
+	for i := first; i < len(toks); i++ {
+		t := toks[i]
+		switch t.text {
+		case "if":
+			f.cyclomatic++
+			if t.depth > 0 && comprehension {
+				f.cognitive++ // comprehension filter
+			} else {
+				f.cognitive += 1 + n // conditional expression
+			}
+		case "for":
+			f.cyclomatic++
+			f.cognitive++
+			comprehension = true
+		case "and", "or":
+			f.cyclomatic++
+			if t.text != lastBool {
+				f.cognitive++
+			}
+			lastBool = t.text
+		case ",", ":", "=":
+			lastBool = ""
+		case "(":
+			if f.isRecursiveCall(toks, i) {
+				f.cognitive++
+			}
+		}
+	}
+}
+
+// pySoftKeyword reports whether toks[i] is used as a keyword rather than
+// a name, judging by the token after it.
+func pySoftKeyword(toks []pyToken, i int) bool {
+	if i+1 >= len(toks) {
+		return false
+	}
+	switch toks[i+1].text {
+	case "=", ".", ":", ",", ")", "]", "}":
+		return false
+	}
+	return true
+}
+
+// isRecursiveCall reports whether the "(" at toks[i] calls f itself.
+func (f *pyFunc) isRecursiveCall(toks []pyToken, i int) bool {
+	if f.name == "" || i < 1 || toks[i-1].text != f.name {
+		return false
+	}
+	if f.method {
+		return i >= 3 && toks[i-2].text == "." &&
+			(toks[i-3].text == "self" || toks[i-3].text == "cls")
+	}
+	return i < 2 || (toks[i-2].text != "." && toks[i-2].text != "def")
+}
+
+// tokenizePython splits src into logical lines.
+func tokenizePython(src string) []pyLine {
+	var lines []pyLine
+	var cur pyLine
+	depth, lineNo, indent := 0, 1, 0
+	atLineStart := true
+
+	add := func(text string) {
+		if len(cur.tokens) == 0 {
+			cur.num = lineNo
+			cur.indent = indent
+		}
+		cur.tokens = append(cur.tokens, pyToken{text: text, depth: depth})
+	}
+
+	for i := 0; i < len(src); {
+		if atLineStart {
+			indent = 0
+			for ; i < len(src) && (src[i] == ' ' || src[i] == '\t' || src[i] == '\f'); i++ {
+				if src[i] == '\t' {
+					indent = indent/8*8 + 8
+				} else if src[i] == ' ' {
+					indent++
+				}
+			}
+			atLineStart = false
+			continue
+		}
+
+		c := src[i]
+		switch {
+		case c == '\n':
+			lineNo++
+			i++
+			if depth == 0 {
+				if len(cur.tokens) > 0 {
+					lines = append(lines, cur)
+				}
+				cur = pyLine{}
+				atLineStart = true
+			}
+		case c == '\\' && strings.HasPrefix(src[i+1:], "\n"):
+			lineNo++
+			i += 2
+		case c == '\\' && strings.HasPrefix(src[i+1:], "\r\n"):
+			lineNo++
+			i += 3
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '\'' || c == '"':
+			i = skipPyString(src, i, &lineNo)
+			add("STR")
+		case isPyIdentByte(c) && !isDigit(c):
+			j := i
+			for j < len(src) && isPyIdentByte(src[j]) {
+				j++
+			}
+			word := src[i:j]
+			if j < len(src) && (src[j] == '\'' || src[j] == '"') && isPyStringPrefix(word) {
+				i = skipPyString(src, j, &lineNo)
+				add("STR")
+			} else {
+				i = j
+				add(word)
+			}
+		case isDigit(c):
+			for i < len(src) && (isPyIdentByte(src[i]) || src[i] == '.') {
+				i++
+			}
+			add("NUM")
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+		case c == '(' || c == '[' || c == '{':
+			add(string(c))
+			depth++
+			i++
+		case c == ')' || c == ']' || c == '}':
+			if depth > 0 {
+				depth--
+			}
+			add(string(c))
+			i++
+		default:
+			add(string(c))
+			i++
+		}
+	}
+	if len(cur.tokens) > 0 {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// skipPyString returns the index just past the string literal starting
+// with the quote at src[i], counting the newlines it spans. An
+// unterminated single-quoted string ends at the end of its line.
+func skipPyString(src string, i int, lineNo *int) int {
+	q := src[i]
+	if strings.HasPrefix(src[i:], strings.Repeat(string(q), 3)) {
+		end := strings.Repeat(string(q), 3)
+		for j := i + 3; j < len(src); j++ {
+			switch {
+			case src[j] == '\\':
+				if j+1 < len(src) && src[j+1] == '\n' {
+					*lineNo++
+				}
+				j++
+			case src[j] == '\n':
+				*lineNo++
+			case strings.HasPrefix(src[j:], end):
+				return j + 3
+			}
+		}
+		return len(src)
+	}
+	for j := i + 1; j < len(src); j++ {
+		switch src[j] {
+		case '\\':
+			if j+1 < len(src) && src[j+1] == '\n' {
+				*lineNo++
+			}
+			j++
+		case q:
+			return j + 1
+		case '\n':
+			return j
+		}
+	}
+	return len(src)
+}
+
+// isPyStringPrefix reports whether word may prefix a string literal, as
+// in r"..." or f'...'.
+func isPyStringPrefix(word string) bool {
+	switch strings.ToLower(word) {
+	case "r", "u", "b", "f", "t", "br", "rb", "fr", "rf", "tr", "rt":
+		return true
+	}
+	return false
+}
+
+func isPyIdentByte(c byte) bool {
+	return c == '_' || c >= 0x80 || isDigit(c) || (c|0x20 >= 'a' && c|0x20 <= 'z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
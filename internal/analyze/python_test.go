@@ -0,0 +1,92 @@
+package analyze
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePython(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "nested functions and methods",
+			src: `class Parser:
+    def parse(self, s):
+        def inner(x):
+            if x:
+                return 1
+        return inner(s)
+
+    class Node:
+        def walk(self):
+            for c in self.children:
+                pass
+
+def top():
+    pass
+`,
+			want: []string{"Parser.parse 1/0", "Parser.parse.inner 2/1", "Parser.Node.walk 2/1", "top 1/0"},
+		},
+		{
+			name: "decorated async def",
+			src: `@app.route("/x")
+@cached
+async def handler(req):
+    if req and req.ok:
+        return 1
+`,
+			want: []string{"handler 3/2"},
+		},
+		{
+			name: "keywords in a triple-quoted string",
+			src: `def f():
+    """
+    if this were code:
+        def g(): pass
+    """
+    return 1
+`,
+			want: []string{"f 1/0"},
+		},
+		{
+			name: "backslash and bracket continuations",
+			src: `def f(a,
+      b):
+    x = a and \
+        b
+    y = [i for i in a
+         if i]
+    return x
+
+def g():
+    pass
+`,
+			want: []string{"f 4/3", "g 1/0"},
+		},
+		{
+			name: "match and case",
+			src: `def f(cmd):
+    match cmd:
+        case "go":
+            return 1
+        case "stop" if cmd:
+            return 2
+        case _:
+            return 0
+`,
+			// The wildcard case adds no path; the guard does.
+			want: []string{"f 4/3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := analyzeSource(t, analyzePython, "a.py", tt.src)
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("functions = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
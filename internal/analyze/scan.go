@@ -0,0 +1,162 @@
+package analyze
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Options controls a scan.
+type Options struct {
+	// Jobs is the number of files analyzed concurrently. Values below 1
+	// use one worker per CPU.
+	Jobs int
+	// Progress, if set, is called after every step of the scan. Calls are
+	// serialized but may come from any goroutine, so it must not block.
+	Progress func(Progress)
+	// CacheDir is the base directory of the persistent analysis cache.
+	// Empty disables caching.
+	CacheDir string
+
+	// Extensions maps file extensions to languages; nil uses
+	// SupportedExtensions.
+	Extensions map[string]string
+	// SkipDirs are directory names never descended into; nil uses SkipDirs.
+	SkipDirs map[string]bool
+	// Include and Exclude are doublestar globs matched against each file's
+	// slash-separated path relative to the root. With Include set, only
+	// matching files are scanned; Exclude wins over Include.
+	Include []string
+	Exclude []string
+	// NoGitignore stops Walk from honoring .gitignore and
+	// .git/info/exclude. .noisemapignore files are always honored.
+	NoGitignore bool
+
+	// Analyzers overrides the analyzer used for a language; languages
+	// not listed use AnalyzerFor.
+	Analyzers map[string]Analyzer
+
+	// Scorer combines complexity and churn into the risk score; nil uses
+	// LinearScorer.
+	Scorer Scorer
+	// Weights and Thresholds configure scoring; zero values use the
+	// defaults.
+	Weights    Weights
+	Thresholds Thresholds
+	// ComplexityMetric selects the complexity measure that feeds the risk
+	// score.
+	ComplexityMetric ComplexityMetric
+	// ChurnMetric selects the churn measure that feeds the risk score.
+	ChurnMetric ChurnMetric
+	// Coupling configures which files count as changing together.
+	Coupling CouplingOptions
+	// History limits churn to part of the git history and sets the width
+	// of its activity buckets.
+	History HistoryWindow
+	// FixRules decide which commits count as bug fixes; zero rules use
+	// DefaultFixRules.
+	FixRules FixRules
+	// Filters leave merges, bots and other commits that are not real work
+	// out of the history.
+	Filters ChurnFilters
+}
+
+// Scan walks root and runs the full analysis pipeline over every supported
+// file, returning scores sorted by risk (highest first). Complexity is
+// computed by a bounded pool of workers while the git history is read and
+// looked up in the background. Cancelling ctx stops all in-flight work and
+// returns ctx.Err().
+func Scan(ctx context.Context, root string, opts Options) ([]FileScore, error) {
+	progress := newProgressTracker(opts.Progress)
+
+	files, err := Walk(root, opts)
+	if err != nil {
+		return nil, err
+	}
+	progress.update(func(p *Progress) { p.FilesFound = len(files) })
+
+	// The cache only saves time, so a cache that cannot be opened is
+	// skipped rather than failing the scan.
+	var cache *Cache
+	if opts.CacheDir != "" {
+		cache, _ = OpenCache(opts.CacheDir, root)
+	}
+
+	complexities := make([]ComplexityResult, len(files))
+	churns := make([]ChurnResult, len(files))
+
+	var historyErr error
+	var churnWG sync.WaitGroup
+	churnWG.Add(1)
+	go func() {
+		defer churnWG.Done()
+		period, err := opts.History.Resolve(ctx, root)
+		if err != nil {
+			historyErr = err
+			return
+		}
+		history, err := cache.history(ctx, root)
+		if err == nil {
+			history, err = history.Filter(ctx, root, opts.Filters)
+		}
+		if err != nil {
+			historyErr = err
+			return
+		}
+		history.ClassifyFixes(opts.FixRules)
+		progress.update(func(p *Progress) { p.HistoryLoaded = true })
+		for i, f := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			churns[i] = AnalyzeChurn(f, history, period)
+			progress.update(func(p *Progress) { p.ChurnDone++ })
+		}
+		attachCouplings(files, churns, history.Couplings(files, period, opts.Coupling))
+	}()
+
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				complexities[i] = analyzeFile(cache, opts.analyzer(files[i].Language), files[i])
+				progress.update(func(p *Progress) {
+					p.ComplexityDone++
+					p.Current = files[i].RelPath
+				})
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	churnWG.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if historyErr != nil {
+		return nil, historyErr
+	}
+	_ = cache.Save()
+
+	scores := Score(files, complexities, churns, opts)
+	SortScores(scores, SortByRisk)
+	return scores, nil
+}
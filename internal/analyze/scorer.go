@@ -1,15 +1,73 @@
 package analyze
 
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
 // RiskBand represents the risk level of a file.
 type RiskBand int
 
 const (
-	RiskLow      RiskBand = iota // 0–30
-	RiskMedium                   // 30–60
-	RiskHigh                     // 60–80
-	RiskCritical                 // 80–100
+	RiskLow      RiskBand = iota // below Thresholds.Medium
+	RiskMedium                   // Thresholds.Medium up to Thresholds.High
+	RiskHigh                     // Thresholds.High up to Thresholds.Critical
+	RiskCritical                 // Thresholds.Critical and above
 )
 
+// Thresholds are the risk scores (0–100) at which each band starts.
+type Thresholds struct {
+	Medium   float64
+	High     float64
+	Critical float64
+}
+
+// DefaultThresholds are the band cutoffs used when none are configured.
+var DefaultThresholds = Thresholds{Medium: 30, High: 60, Critical: 80}
+
+// Band returns the risk band a score falls into.
+func (t Thresholds) Band(score float64) RiskBand {
+	switch {
+	case score >= t.Critical:
+		return RiskCritical
+	case score >= t.High:
+		return RiskHigh
+	case score >= t.Medium:
+		return RiskMedium
+	default:
+		return RiskLow
+	}
+}
+
+// Validate checks that the cutoffs are ascending and within 0–100.
+func (t Thresholds) Validate() error {
+	if !(0 < t.Medium && t.Medium < t.High && t.High < t.Critical && t.Critical <= 100) {
+		return fmt.Errorf("band thresholds must satisfy 0 < medium < high < critical <= 100, got %g/%g/%g",
+			t.Medium, t.High, t.Critical)
+	}
+	return nil
+}
+
+// Weights are the relative contributions of complexity and churn to the
+// risk score. They are normalized by their sum, so only the ratio matters.
+type Weights struct {
+	Complexity float64
+	Churn      float64
+}
+
+// DefaultWeights favor complexity over churn.
+var DefaultWeights = Weights{Complexity: 0.6, Churn: 0.4}
+
+// Validate checks that the weights are usable.
+func (w Weights) Validate() error {
+	if w.Complexity < 0 || w.Churn < 0 || w.Complexity+w.Churn == 0 {
+		return fmt.Errorf("weights must be non-negative and not both zero, got complexity=%g churn=%g",
+			w.Complexity, w.Churn)
+	}
+	return nil
+}
+
 func (r RiskBand) String() string {
 	switch r {
 	case RiskLow:
@@ -24,6 +82,16 @@ func (r RiskBand) String() string {
 	return "Unknown"
 }
 
+// ParseRiskBand parses a band name such as "high" (case-insensitive).
+func ParseRiskBand(s string) (RiskBand, error) {
+	for _, b := range []RiskBand{RiskLow, RiskMedium, RiskHigh, RiskCritical} {
+		if strings.EqualFold(s, b.String()) {
+			return b, nil
+		}
+	}
+	return RiskLow, fmt.Errorf("unknown risk band %q (want low, medium, high or critical)", s)
+}
+
 func (r RiskBand) Emoji() string {
 	switch r {
 	case RiskLow:
@@ -38,6 +106,85 @@ func (r RiskBand) Emoji() string {
 	return "⚪"
 }
 
+// ComplexityMetric selects which complexity measure feeds the risk score.
+type ComplexityMetric int
+
+const (
+	MetricCyclomatic ComplexityMetric = iota // decision points (the default)
+	MetricCognitive                          // Sonar-style cognitive complexity
+)
+
+func (m ComplexityMetric) String() string {
+	if m == MetricCognitive {
+		return "cognitive"
+	}
+	return "cyclomatic"
+}
+
+// ParseComplexityMetric parses "cyclomatic" or "cognitive".
+func ParseComplexityMetric(s string) (ComplexityMetric, error) {
+	for _, m := range []ComplexityMetric{MetricCyclomatic, MetricCognitive} {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return MetricCyclomatic, fmt.Errorf("unknown complexity metric %q (want cyclomatic or cognitive)", s)
+}
+
+// ChurnMetric selects which churn measure feeds the risk score.
+type ChurnMetric int
+
+const (
+	ChurnCommits  ChurnMetric = iota // commits touching the file (the default)
+	ChurnLines                       // lines added plus lines deleted
+	ChurnAdded                       // lines added
+	ChurnDeleted                     // lines deleted
+	ChurnNet                         // lines added minus deleted; shrinking counts as zero
+	ChurnRelative                    // lines churned per line of the current file
+	ChurnDecayed                     // commits weighted by age; see ChurnResult.Decayed
+	ChurnFixes                       // commits classified as bug fixes
+)
+
+var churnMetricNames = []string{"commits", "lines", "added", "deleted", "net", "relative", "decayed", "fixes"}
+
+func (m ChurnMetric) String() string {
+	if m < 0 || int(m) >= len(churnMetricNames) {
+		return churnMetricNames[0]
+	}
+	return churnMetricNames[m]
+}
+
+// ParseChurnMetric parses a churn metric name such as "lines".
+func ParseChurnMetric(s string) (ChurnMetric, error) {
+	for i, name := range churnMetricNames {
+		if strings.EqualFold(s, name) {
+			return ChurnMetric(i), nil
+		}
+	}
+	return ChurnCommits, fmt.Errorf("unknown churn metric %q (want %s)", s, strings.Join(churnMetricNames, ", "))
+}
+
+// Value returns the file's churn under metric m.
+func (c ChurnResult) Value(m ChurnMetric) float64 {
+	switch m {
+	case ChurnLines:
+		return float64(c.LinesChurned())
+	case ChurnAdded:
+		return float64(c.LinesAdded)
+	case ChurnDeleted:
+		return float64(c.LinesDeleted)
+	case ChurnNet:
+		return math.Max(0, float64(c.NetLines()))
+	case ChurnRelative:
+		return c.RelativeChurn
+	case ChurnDecayed:
+		return c.Decayed
+	case ChurnFixes:
+		return float64(c.FixCommits)
+	}
+	return float64(c.TotalCommits)
+}
+
 // FileScore is the fully analyzed result for a single file.
 type FileScore struct {
 	File             FileInfo
@@ -48,57 +195,51 @@ type FileScore struct {
 	ChurnNorm      float64
 	RiskScore      float64
 	RiskBand       RiskBand
+	// Explanation accounts for RiskScore and RiskBand.
+	Explanation Explanation
 }
 
-// Score computes composite risk scores across all files.
-func Score(files []FileInfo, complexities []ComplexityResult, churns []ChurnResult) []FileScore {
+// Score computes composite risk scores, and their explanations, across all
+// files using the scorer, weights, thresholds and metrics in opts. A nil scorer uses
+// LinearScorer, and zero-valued weights or thresholds fall back to the
+// defaults. Score also fills in each ChurnResult's RelativeChurn from the
+// file's line count.
+func Score(files []FileInfo, complexities []ComplexityResult, churns []ChurnResult, opts Options) []FileScore {
 	if len(files) == 0 {
 		return nil
 	}
+	sc, w, t := opts.Scorer, opts.Weights, opts.Thresholds
+	if sc == nil {
+		sc = LinearScorer{}
+	}
+	if w == (Weights{}) {
+		w = DefaultWeights
+	}
+	if t == (Thresholds{}) {
+		t = DefaultThresholds
+	}
 
 	scores := make([]FileScore, len(files))
+	complexity := make([]float64, len(files))
+	churn := make([]float64, len(files))
 	for i := range files {
 		scores[i] = FileScore{
 			File:             files[i],
 			ComplexityResult: complexities[i],
 			ChurnResult:      churns[i],
 		}
-	}
-
-	// Find max values for normalization
-	maxC, maxCh := 1, 1
-	for _, s := range scores {
-		if s.ComplexityResult.Total > maxC {
-			maxC = s.ComplexityResult.Total
-		}
-		if s.ChurnResult.TotalCommits > maxCh {
-			maxCh = s.ChurnResult.TotalCommits
+		if lines := complexities[i].Lines; lines > 0 {
+			scores[i].ChurnResult.RelativeChurn = float64(churns[i].LinesChurned()) / float64(lines)
 		}
+		complexity[i] = float64(complexities[i].Value(opts.ComplexityMetric))
+		churn[i] = scores[i].ChurnResult.Value(opts.ChurnMetric)
 	}
 
-	// Normalize and compute risk
+	sc.Score(scores, complexity, churn, w)
 	for i := range scores {
-		cn := float64(scores[i].ComplexityResult.Total) / float64(maxC) * 100
-		ch := float64(scores[i].ChurnResult.TotalCommits) / float64(maxCh) * 100
-		scores[i].ComplexityNorm = cn
-		scores[i].ChurnNorm = ch
-
-		// Weighted composite: complexity matters more than churn
-		risk := 0.6*cn + 0.4*ch
-		scores[i].RiskScore = risk
-
-		switch {
-		case risk >= 80:
-			scores[i].RiskBand = RiskCritical
-		case risk >= 60:
-			scores[i].RiskBand = RiskHigh
-		case risk >= 30:
-			scores[i].RiskBand = RiskMedium
-		default:
-			scores[i].RiskBand = RiskLow
-		}
+		scores[i].RiskBand = t.Band(scores[i].RiskScore)
 	}
-
+	explain(scores, complexity, churn, sc, w, t, opts)
 	return scores
 }
 
@@ -144,9 +285,10 @@ func shouldSwap(a, b FileScore, by SortBy) bool {
 	case SortByRisk:
 		return b.RiskScore > a.RiskScore
 	case SortByComplexity:
-		return b.ComplexityResult.Total > a.ComplexityResult.Total
+		// The normalized score follows the configured metric.
+		return b.ComplexityNorm > a.ComplexityNorm
 	case SortByChurn:
-		return b.ChurnResult.TotalCommits > a.ChurnResult.TotalCommits
+		return b.ChurnNorm > a.ChurnNorm
 	case SortByName:
 		return b.File.RelPath < a.File.RelPath
 	}
@@ -0,0 +1,240 @@
+package analyze
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scorer turns each file's raw complexity and churn into the normalized
+// ComplexityNorm and ChurnNorm signals and the RiskScore, all on a 0–100
+// scale. A scorer sees every file of a scan at once, so it may normalize
+// against the whole distribution. Bands are assigned afterwards from the
+// RiskScore.
+type Scorer interface {
+	// Name is the name the scorer is selected by.
+	Name() string
+	// Score sets ComplexityNorm, ChurnNorm and RiskScore on every file.
+	// complexity[i] and churn[i] are the raw values of scores[i] under the
+	// configured metrics; w has been validated.
+	Score(scores []FileScore, complexity, churn []float64, w Weights)
+}
+
+// DefaultScorer is the name of the scorer used when none is configured.
+const DefaultScorer = "linear"
+
+// LinearScorer normalizes each signal against the highest value in the
+// scan and takes their weighted mean. A RiskScore of 50 means the file
+// sits, on average over the weighted signals, at half of the worst file;
+// one outlier compresses everything else towards 0.
+type LinearScorer struct{}
+
+func (LinearScorer) Name() string { return "linear" }
+
+func (LinearScorer) Score(scores []FileScore, complexity, churn []float64, w Weights) {
+	setNorms(scores, linearNorms(complexity), linearNorms(churn), w)
+}
+
+func (LinearScorer) Explain(scores []FileScore, complexity, churn []float64, w Weights) {
+	explainMean(scores, complexity, churn, func(d distribution, i int, limit float64) float64 {
+		return limit / 100 * d.othersMax(i)
+	})
+}
+
+// PercentileScorer replaces each signal by its percentile rank and takes
+// their weighted mean. A signal of 70 means the file's value is at least
+// that of 70% of the other files, so a RiskScore of 70 ranks the file
+// above roughly 70% of the scan regardless of how far apart the raw values
+// are. Files with a value of 0 always rank 0. Scores spread evenly, so
+// every scan has files in every band.
+type PercentileScorer struct{}
+
+func (PercentileScorer) Name() string { return "percentile" }
+
+func (PercentileScorer) Score(scores []FileScore, complexity, churn []float64, w Weights) {
+	setNorms(scores, percentileNorms(complexity), percentileNorms(churn), w)
+}
+
+func (PercentileScorer) Explain(scores []FileScore, complexity, churn []float64, w Weights) {
+	explainMean(scores, complexity, churn, func(d distribution, i int, limit float64) float64 {
+		others := len(d.values) - 1
+		if others == 0 {
+			return 0
+		}
+		// The file must rank above at most k other files.
+		k := min(int(math.Ceil(limit/100*float64(others)))-1, others-1)
+		return math.Max(0, d.othersNth(i, k))
+	})
+}
+
+// LogScorer normalizes log(1+value) against the log of the highest value
+// and takes the weighted mean. A signal of 50 means the file's value is
+// about the square root of the highest one, so outliers no longer push
+// the rest of the scan towards 0 and differences among small values still
+// show.
+type LogScorer struct{}
+
+func (LogScorer) Name() string { return "log" }
+
+func (LogScorer) Score(scores []FileScore, complexity, churn []float64, w Weights) {
+	setNorms(scores, logNorms(complexity), logNorms(churn), w)
+}
+
+func (LogScorer) Explain(scores []FileScore, complexity, churn []float64, w Weights) {
+	explainMean(scores, complexity, churn, func(d distribution, i int, limit float64) float64 {
+		return math.Expm1(limit / 100 * math.Log1p(d.othersMax(i)))
+	})
+}
+
+// HotspotScorer ranks files by complexity × churn, after Adam Tornhill's
+// hotspots: code that is both hard to understand and changed often. The
+// signals are normalized as in LinearScorer and combined as a weighted
+// geometric mean, which with equal weights orders files exactly like the
+// plain product. The RiskScore is then rescaled so that the worst hotspot
+// scores 100 and a score of 50 means half its product. A file lacking
+// either signal scores 0.
+type HotspotScorer struct{}
+
+func (HotspotScorer) Name() string { return "hotspot" }
+
+func (HotspotScorer) Score(scores []FileScore, complexity, churn []float64, w Weights) {
+	cn, ch := linearNorms(complexity), linearNorms(churn)
+	a := w.Complexity / (w.Complexity + w.Churn)
+	maxRisk := 0.0
+	for i := range scores {
+		scores[i].ComplexityNorm = cn[i]
+		scores[i].ChurnNorm = ch[i]
+		risk := math.Pow(cn[i], a) * math.Pow(ch[i], 1-a)
+		scores[i].RiskScore = risk
+		maxRisk = math.Max(maxRisk, risk)
+	}
+	if maxRisk > 0 {
+		for i := range scores {
+			scores[i].RiskScore *= 100 / maxRisk
+		}
+	}
+}
+
+// Explain credits each signal with its weight's share of the RiskScore,
+// since a product has no additive parts: a signal with weight a moves the
+// score by a% for every 1% it changes.
+func (HotspotScorer) Explain(scores []FileScore, complexity, churn []float64, w Weights) {
+	a := w.Complexity / (w.Complexity + w.Churn)
+	// The RiskScore is the file's product over the highest product,
+	// whatever the signals are normalized against.
+	products := make([]float64, len(scores))
+	for i := range scores {
+		products[i] = math.Pow(complexity[i], a) * math.Pow(churn[i], 1-a)
+	}
+	dp := newDistribution(products)
+	for i := range scores {
+		e := &scores[i].Explanation
+		e.Complexity.Points = a * scores[i].RiskScore
+		e.Churn.Points = (1 - a) * scores[i].RiskScore
+		if e.Floor <= 0 {
+			continue
+		}
+		target := e.Floor / 100 * dp.othersMax(i)
+		for _, sig := range []struct {
+			own      *Signal
+			exponent float64
+			other    float64
+		}{{&e.Complexity, a, churn[i]}, {&e.Churn, 1 - a, complexity[i]}} {
+			if sig.exponent == 0 {
+				continue
+			}
+			sig.own.Lowerable = true
+			if target > 0 {
+				sig.own.Below = math.Pow(target/math.Pow(sig.other, 1-sig.exponent), 1/sig.exponent)
+			}
+		}
+	}
+}
+
+// setNorms stores the normalized signals and their weighted mean.
+func setNorms(scores []FileScore, cn, ch []float64, w Weights) {
+	for i := range scores {
+		scores[i].ComplexityNorm = cn[i]
+		scores[i].ChurnNorm = ch[i]
+		scores[i].RiskScore = (w.Complexity*cn[i] + w.Churn*ch[i]) / (w.Complexity + w.Churn)
+	}
+}
+
+// linearNorms scales values so that the highest is 100. All values are 0
+// when the highest is.
+func linearNorms(values []float64) []float64 {
+	maxV := 0.0
+	for _, v := range values {
+		maxV = math.Max(maxV, v)
+	}
+	out := make([]float64, len(values))
+	if maxV > 0 {
+		for i, v := range values {
+			out[i] = v / maxV * 100
+		}
+	}
+	return out
+}
+
+// logNorms is linearNorms on log(1+value).
+func logNorms(values []float64) []float64 {
+	logs := make([]float64, len(values))
+	for i, v := range values {
+		logs[i] = math.Log1p(math.Max(0, v))
+	}
+	return linearNorms(logs)
+}
+
+// percentileNorms returns, for each value, the percentage of the other
+// values that are at most as high. Zero values stay 0.
+func percentileNorms(values []float64) []float64 {
+	d := newDistribution(values)
+	out := make([]float64, len(values))
+	for i, v := range values {
+		switch {
+		case v <= 0:
+		case len(values) == 1:
+			out[i] = 100
+		default:
+			out[i] = float64(d.rank(i, v)) / float64(len(values)-1) * 100
+		}
+	}
+	return out
+}
+
+var scorers = struct {
+	sync.RWMutex
+	m map[string]Scorer
+}{m: map[string]Scorer{
+	"linear":     LinearScorer{},
+	"percentile": PercentileScorer{},
+	"log":        LogScorer{},
+	"hotspot":    HotspotScorer{},
+}}
+
+// RegisterScorer makes s available under s.Name(), replacing any scorer
+// of the same name. It must be called before scanning starts.
+func RegisterScorer(s Scorer) {
+	scorers.Lock()
+	defer scorers.Unlock()
+	scorers.m[s.Name()] = s
+}
+
+// LookupScorer returns the scorer registered under name.
+func LookupScorer(name string) (Scorer, error) {
+	scorers.RLock()
+	defer scorers.RUnlock()
+	for n, s := range scorers.m {
+		if strings.EqualFold(n, name) {
+			return s, nil
+		}
+	}
+	names := make([]string, 0, len(scorers.m))
+	for n := range scorers.m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("unknown scorer %q (want one of %s)", name, strings.Join(names, ", "))
+}
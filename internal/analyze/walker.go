@@ -1,24 +1,13 @@
 package analyze
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
-)
 
-// SupportedExtensions maps file extensions to language names.
-var SupportedExtensions = map[string]string{
-	".go":   "Go",
-	".js":   "JavaScript",
-	".ts":   "TypeScript",
-	".py":   "Python",
-	".java": "Java",
-	".rs":   "Rust",
-	".c":    "C",
-	".cpp":  "C++",
-	".rb":   "Ruby",
-	".php":  "PHP",
-}
+	"github.com/bmatcuk/doublestar/v4"
+)
 
 // SkipDirs are directory names to skip during the walk.
 var SkipDirs = map[string]bool{
@@ -40,8 +29,31 @@ type FileInfo struct {
 	Language string
 }
 
-// Walk scans root recursively and returns all supported source files.
-func Walk(root string) ([]FileInfo, error) {
+// Walk scans root recursively and returns all supported source files,
+// recognized by extension or, for files without one, by shebang.
+// opts.Extensions and opts.SkipDirs replace the package defaults when set,
+// and opts.Include/opts.Exclude filter files by their slash-separated path
+// relative to root. Files ignored by git (.gitignore files at any level and
+// .git/info/exclude) or by a .noisemapignore file are skipped.
+func Walk(root string, opts Options) ([]FileInfo, error) {
+	extensions := opts.Extensions
+	if extensions == nil {
+		extensions = SupportedExtensions
+	}
+	skipDirs := opts.SkipDirs
+	if skipDirs == nil {
+		skipDirs = SkipDirs
+	}
+
+	// Extensionless scripts are recognized by their shebang, but only in
+	// languages that still have an extension mapped.
+	languages := make(map[string]bool)
+	for _, lang := range extensions {
+		languages[lang] = true
+	}
+
+	ignore := newIgnoreMatcher(root, !opts.NoGitignore)
+
 	var files []FileInfo
 
 	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
@@ -49,20 +61,34 @@ func Walk(root string) ([]FileInfo, error) {
 			return nil // skip unreadable entries
 		}
 
+		relPath, _ := filepath.Rel(root, path)
+		slashPath := filepath.ToSlash(relPath)
+
 		if d.IsDir() {
-			if SkipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") {
+			if path != root && (skipDirs[d.Name()] || strings.HasPrefix(d.Name(), ".") ||
+				matchAny(opts.Exclude, slashPath) || ignore.ignored(path, true)) {
 				return filepath.SkipDir
 			}
+			ignore.loadDir(path)
 			return nil
 		}
 
 		ext := strings.ToLower(filepath.Ext(d.Name()))
-		lang, ok := SupportedExtensions[ext]
+		lang, ok := extensions[ext]
+		if ext == "" {
+			lang, ok = shebangLanguage(path)
+			ok = ok && languages[lang]
+		}
 		if !ok {
 			return nil
 		}
 
-		relPath, _ := filepath.Rel(root, path)
+		if len(opts.Include) > 0 && !matchAny(opts.Include, slashPath) {
+			return nil
+		}
+		if matchAny(opts.Exclude, slashPath) || ignore.ignored(path, false) {
+			return nil
+		}
 
 		files = append(files, FileInfo{
 			Path:     path,
@@ -74,3 +100,30 @@ func Walk(root string) ([]FileInfo, error) {
 
 	return files, err
 }
+
+// matchAny reports whether path matches any of the doublestar patterns.
+// Patterns are validated when options are built, so match errors are
+// treated as no match.
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if ok, _ := doublestar.Match(p, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// shebangLanguage detects the language of the script at path from its
+// "#!" line.
+func shebangLanguage(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	buf := make([]byte, 256)
+	n, _ := io.ReadFull(f, buf)
+	line, _, _ := strings.Cut(string(buf[:n]), "\n")
+	return LanguageForShebang(strings.TrimSuffix(line, "\r"))
+}
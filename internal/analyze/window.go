@@ -0,0 +1,197 @@
+package analyze
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ChurnBuckets is the number of activity buckets in a ChurnResult.
+const ChurnBuckets = 12
+
+// Granularity is the width of each churn activity bucket.
+type Granularity int
+
+const (
+	GranularityMonth   Granularity = iota // calendar months (the default)
+	GranularityWeek                       // seven days
+	GranularityQuarter                    // three calendar months
+)
+
+func (g Granularity) String() string {
+	switch g {
+	case GranularityWeek:
+		return "week"
+	case GranularityQuarter:
+		return "quarter"
+	}
+	return "month"
+}
+
+// ParseGranularity parses "week", "month" or "quarter".
+func ParseGranularity(s string) (Granularity, error) {
+	for _, g := range []Granularity{GranularityWeek, GranularityMonth, GranularityQuarter} {
+		if strings.EqualFold(s, g.String()) {
+			return g, nil
+		}
+	}
+	return GranularityMonth, fmt.Errorf("unknown granularity %q (want week, month or quarter)", s)
+}
+
+// step moves t back by n buckets.
+func (g Granularity) step(t time.Time, n int) time.Time {
+	switch g {
+	case GranularityWeek:
+		return t.AddDate(0, 0, -7*n)
+	case GranularityQuarter:
+		return t.AddDate(0, -3*n, 0)
+	}
+	return t.AddDate(0, -n, 0)
+}
+
+// DefaultHalfLife is the age at which a commit counts half towards
+// decayed churn when no half-life is configured.
+const DefaultHalfLife = 90 * 24 * time.Hour
+
+// HistoryWindow selects the part of the git history that churn is
+// computed from, and how it is weighted. Since and Until are dates such
+// as "2024-01-31" or git revisions such as "v1.2.0", which stand for their
+// commit time; empty means unbounded. A date given as Until includes that
+// whole day.
+type HistoryWindow struct {
+	Since       string
+	Until       string
+	Granularity Granularity
+	// HalfLife is the age at which a commit counts half towards decayed
+	// churn; zero uses DefaultHalfLife.
+	HalfLife time.Duration
+}
+
+// Period is a HistoryWindow resolved to points in time. Zero times are
+// unbounded.
+type Period struct {
+	Since       time.Time
+	Until       time.Time
+	Granularity Granularity
+	HalfLife    time.Duration
+}
+
+// Resolve turns the window's dates and revisions into a Period, looking
+// revisions up in the repository containing root.
+func (w HistoryWindow) Resolve(ctx context.Context, root string) (Period, error) {
+	p := Period{Granularity: w.Granularity, HalfLife: w.HalfLife}
+	if p.HalfLife <= 0 {
+		p.HalfLife = DefaultHalfLife
+	}
+	var err error
+	if p.Since, err = resolveBound(ctx, root, w.Since, false); err != nil {
+		return p, fmt.Errorf("since: %w", err)
+	}
+	if p.Until, err = resolveBound(ctx, root, w.Until, true); err != nil {
+		return p, fmt.Errorf("until: %w", err)
+	}
+	if !p.Since.IsZero() && !p.Until.IsZero() && !p.Since.Before(p.Until) {
+		return p, fmt.Errorf("since (%s) must be before until (%s)",
+			p.Since.Format(time.DateTime), p.Until.Format(time.DateTime))
+	}
+	return p, nil
+}
+
+// resolveBound parses s as a date or, failing that, looks it up as a git
+// revision. endOfDay moves a bare date to the end of that day.
+func resolveBound(ctx context.Context, root, s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	// A leading dash would be read as an option.
+	if !strings.HasPrefix(s, "-") {
+		out, err := exec.CommandContext(ctx, "git", "-C", root, "log", "-1", "--format=%ct", s+"^{commit}", "--").Output()
+		if err == nil {
+			if secs, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64); err == nil {
+				return time.Unix(secs, 0), nil
+			}
+		}
+		if ctx.Err() != nil {
+			return time.Time{}, ctx.Err()
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is neither a date (YYYY-MM-DD) nor a git revision", s)
+}
+
+// contains reports whether a commit made at unix falls in the period.
+func (p Period) contains(unix int64) bool {
+	t := time.Unix(unix, 0)
+	return (p.Since.IsZero() || t.After(p.Since)) && (p.Until.IsZero() || !t.After(p.Until))
+}
+
+// end returns the end of the period: Until, or now when open-ended.
+func (p Period) end(now time.Time) time.Time {
+	if p.Until.IsZero() {
+		return now
+	}
+	return p.Until
+}
+
+// decay returns the weight of a commit made at unix in decayed churn:
+// 1 at the end of the period, halving with every HalfLife of age.
+func (p Period) decay(unix int64, now time.Time) float64 {
+	age := p.end(now).Sub(time.Unix(unix, 0))
+	if age <= 0 || p.HalfLife <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(p.HalfLife))
+}
+
+// ParseHalfLife parses a half-life such as "90d", "2w", "6m" or "1y",
+// where a month is 30 days and a year 365, or a Go duration like "720h".
+func ParseHalfLife(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'm': 30 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if unit, ok := units[s[n-1]]; ok {
+			if v, err := strconv.ParseFloat(s[:n-1], 64); err == nil && v > 0 {
+				return time.Duration(v * float64(unit)), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid half-life %q (want e.g. 90d, 2w, 6m or 1y)", s)
+}
+
+// FormatHalfLife formats d in whole days, the unit half-lives are usually
+// given in, or as a Go duration otherwise.
+func FormatHalfLife(d time.Duration) string {
+	const day = 24 * time.Hour
+	if d%day == 0 {
+		return strconv.FormatInt(int64(d/day), 10) + "d"
+	}
+	return d.String()
+}
+
+// bucketBounds returns the edges of the ChurnBuckets buckets ending at
+// p.Until, or at now for an open-ended period.
+func (p Period) bucketBounds(now time.Time) []time.Time {
+	end := p.end(now)
+	bounds := make([]time.Time, ChurnBuckets+1)
+	for i := range bounds {
+		bounds[i] = p.Granularity.step(end, ChurnBuckets-i)
+	}
+	return bounds
+}
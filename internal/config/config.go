@@ -0,0 +1,411 @@
+// Package config loads the per-repository .noisemap.yml file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/meetsoni15/noisemap/internal/analyze"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames are the names a config file may have, in lookup order.
+var FileNames = []string{".noisemap.yml", ".noisemap.yaml"}
+
+// Config is the contents of a .noisemap.yml file. Every field is optional;
+// unset fields keep the built-in defaults.
+type Config struct {
+	// Extensions adds or overrides extension-to-language mappings on top of
+	// the defaults. An empty language removes the extension.
+	Extensions map[string]string `yaml:"extensions"`
+	// SkipDirs replaces the default list of directory names to skip.
+	SkipDirs []string `yaml:"skip_dirs"`
+	// Include and Exclude are doublestar globs on paths relative to the
+	// scan root.
+	Include []string `yaml:"include"`
+	Exclude []string `yaml:"exclude"`
+	// Gitignore controls whether .gitignore and .git/info/exclude are
+	// honored (default true). .noisemapignore files always are.
+	Gitignore *bool `yaml:"gitignore"`
+
+	// Analyzers picks the analyzer for a language by name, e.g.
+	// {Python: generic}. Language names are matched ignoring case.
+	Analyzers map[string]string `yaml:"analyzers"`
+	// Go configures the go analyzer.
+	Go *GoConfig `yaml:"go"`
+	// ComplexityMetric is "cyclomatic" (default) or "cognitive".
+	ComplexityMetric string `yaml:"complexity_metric"`
+	// ChurnMetric is "commits" (default), "lines", "added", "deleted",
+	// "net", "relative", "decayed" or "fixes".
+	ChurnMetric string `yaml:"churn_metric"`
+
+	// Coupling configures which files count as changing together.
+	Coupling *Coupling `yaml:"coupling"`
+	// History limits churn to part of the git history.
+	History *History `yaml:"history"`
+	// Fixes configures which commits count as bug fixes.
+	Fixes *Fixes `yaml:"fixes"`
+	// Commits configures which commits are left out of churn.
+	Commits *Commits `yaml:"commits"`
+
+	// Scorer combines complexity and churn into the risk score: "linear"
+	// (default), "percentile", "log" or "hotspot".
+	Scorer  string   `yaml:"scorer"`
+	Weights *Weights `yaml:"weights"`
+	Bands   *Bands   `yaml:"bands"`
+}
+
+// History selects the part of the git history that churn covers.
+type History struct {
+	// Since and Until are dates (YYYY-MM-DD) or git revisions.
+	Since string `yaml:"since"`
+	Until string `yaml:"until"`
+	// Granularity is "week", "month" (default) or "quarter".
+	Granularity string `yaml:"granularity"`
+	// HalfLife is the age at which a commit counts half in decayed churn,
+	// e.g. "90d" (default), "2w", "6m" or "1y".
+	HalfLife string `yaml:"half_life"`
+}
+
+// Fixes configures bug-fix commit classification. Each list replaces the
+// default when set.
+type Fixes struct {
+	// Types are conventional-commit types that mark a fix.
+	Types []string `yaml:"types"`
+	// Patterns are case-insensitive regular expressions matched against
+	// commit subjects without a conventional-commit prefix.
+	Patterns []string `yaml:"patterns"`
+}
+
+// Commits configures which commits churn, coupling and authorship skip.
+type Commits struct {
+	// KeepMerges counts merge commits (default false).
+	KeepMerges *bool `yaml:"keep_merges"`
+	// IgnoreAuthors are case-insensitive regular expressions matched
+	// against "Name <email>". They replace the default bot patterns; an
+	// empty list keeps every author.
+	IgnoreAuthors []string `yaml:"ignore_authors"`
+	// IgnoreRevsFile lists commits to skip, relative to the top of the
+	// repository (default .git-blame-ignore-revs, if it exists).
+	IgnoreRevsFile string `yaml:"ignore_revs_file"`
+	// MaxFiles skips commits touching more files than this (default 0,
+	// no limit).
+	MaxFiles *int `yaml:"max_files"`
+}
+
+// Coupling configures temporal coupling analysis.
+type Coupling struct {
+	// MinShared is the number of commits two files must share (default 3).
+	MinShared *int `yaml:"min_shared"`
+	// MaxFiles skips commits touching more files than this (default 50).
+	MaxFiles *int `yaml:"max_files"`
+}
+
+// GoConfig configures the go analyzer.
+type GoConfig struct {
+	// ClosuresInParent also counts function literals towards the function
+	// containing them (default false). Literals are always reported on
+	// their own as well.
+	ClosuresInParent *bool `yaml:"closures_in_parent"`
+}
+
+// Weights configures the relative weight of each scoring signal.
+type Weights struct {
+	Complexity *float64 `yaml:"complexity"`
+	Churn      *float64 `yaml:"churn"`
+}
+
+// Bands configures the risk score at which each band starts.
+type Bands struct {
+	Medium   *float64 `yaml:"medium"`
+	High     *float64 `yaml:"high"`
+	Critical *float64 `yaml:"critical"`
+}
+
+// Find looks for a config file in dir and each of its parents and returns
+// the first path found, or "" if there is none.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, name := range FileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads and validates the config file at path. Unknown keys are
+// reported as errors so that typos do not silently fall back to defaults.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// language returns the spelling of the language called name, ignoring
+// case, among the registered languages and those named in Extensions.
+func (c *Config) language(name string) (string, bool) {
+	if l, ok := analyze.LookupLanguage(name); ok {
+		return l, true
+	}
+	for _, l := range c.Extensions {
+		if l != "" && strings.EqualFold(l, name) {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// validate checks values that the YAML types alone cannot.
+func (c *Config) validate() error {
+	for ext := range c.Extensions {
+		if !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("extensions: %q must start with a dot", ext)
+		}
+	}
+	for _, p := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if !doublestar.ValidatePattern(p) {
+			return fmt.Errorf("invalid glob %q", p)
+		}
+	}
+
+	for lang, name := range c.Analyzers {
+		if _, ok := c.language(lang); !ok {
+			return fmt.Errorf("unknown language %q in analyzers", lang)
+		}
+		if _, err := analyze.LookupAnalyzer(name); err != nil {
+			return fmt.Errorf("analyzers: %s: %w", lang, err)
+		}
+	}
+	if c.ComplexityMetric != "" {
+		if _, err := analyze.ParseComplexityMetric(c.ComplexityMetric); err != nil {
+			return fmt.Errorf("complexity_metric: %w", err)
+		}
+	}
+	if c.ChurnMetric != "" {
+		if _, err := analyze.ParseChurnMetric(c.ChurnMetric); err != nil {
+			return fmt.Errorf("churn_metric: %w", err)
+		}
+	}
+
+	if c.Coupling != nil {
+		if v := c.Coupling.MinShared; v != nil && *v < 1 {
+			return fmt.Errorf("coupling: min_shared must be at least 1, got %d", *v)
+		}
+		if v := c.Coupling.MaxFiles; v != nil && *v < 2 {
+			return fmt.Errorf("coupling: max_files must be at least 2, got %d", *v)
+		}
+	}
+
+	if c.History != nil && c.History.Granularity != "" {
+		if _, err := analyze.ParseGranularity(c.History.Granularity); err != nil {
+			return fmt.Errorf("history: %w", err)
+		}
+	}
+	if c.History != nil && c.History.HalfLife != "" {
+		if _, err := analyze.ParseHalfLife(c.History.HalfLife); err != nil {
+			return fmt.Errorf("history: %w", err)
+		}
+	}
+
+	if c.Fixes != nil {
+		if _, err := analyze.CompileFixPatterns(c.Fixes.Patterns); err != nil {
+			return fmt.Errorf("fixes: %w", err)
+		}
+	}
+
+	if c.Commits != nil {
+		if _, err := analyze.CompileAuthorPatterns(c.Commits.IgnoreAuthors); err != nil {
+			return fmt.Errorf("commits: %w", err)
+		}
+		if v := c.Commits.MaxFiles; v != nil && *v < 0 {
+			return fmt.Errorf("commits: max_files must not be negative, got %d", *v)
+		}
+	}
+
+	if c.Scorer != "" {
+		if _, err := analyze.LookupScorer(c.Scorer); err != nil {
+			return fmt.Errorf("scorer: %w", err)
+		}
+	}
+
+	var opts analyze.Options
+	c.Apply(&opts)
+	if c.Weights != nil {
+		if err := opts.Weights.Validate(); err != nil {
+			return fmt.Errorf("weights: %w", err)
+		}
+	}
+	if c.Bands != nil {
+		if err := opts.Thresholds.Validate(); err != nil {
+			return fmt.Errorf("bands: %w", err)
+		}
+	}
+	return nil
+}
+
+// Apply copies every value set in the config into opts, filling the rest
+// from the defaults where the config only overrides part of a setting.
+func (c *Config) Apply(opts *analyze.Options) {
+	if c.Extensions != nil {
+		exts := make(map[string]string, len(analyze.SupportedExtensions))
+		for ext, lang := range analyze.SupportedExtensions {
+			exts[ext] = lang
+		}
+		for ext, lang := range c.Extensions {
+			ext = strings.ToLower(ext)
+			if lang == "" {
+				delete(exts, ext)
+			} else {
+				exts[ext] = lang
+			}
+		}
+		opts.Extensions = exts
+	}
+
+	if c.SkipDirs != nil {
+		opts.SkipDirs = make(map[string]bool, len(c.SkipDirs))
+		for _, d := range c.SkipDirs {
+			opts.SkipDirs[d] = true
+		}
+	}
+
+	if c.Include != nil {
+		opts.Include = c.Include
+	}
+	if c.Exclude != nil {
+		opts.Exclude = c.Exclude
+	}
+	if c.Gitignore != nil {
+		opts.NoGitignore = !*c.Gitignore
+	}
+
+	if c.Analyzers != nil {
+		opts.Analyzers = make(map[string]analyze.Analyzer, len(c.Analyzers))
+		for lang, name := range c.Analyzers {
+			l, ok := c.language(lang)
+			if a, err := analyze.LookupAnalyzer(name); err == nil && ok {
+				opts.Analyzers[l] = a
+			}
+		}
+	}
+
+	// The go settings apply unless Go was switched to another analyzer.
+	if c.Go != nil && c.Go.ClosuresInParent != nil {
+		if a, ok := opts.Analyzers["Go"]; !ok || a.Name() == "go" {
+			if opts.Analyzers == nil {
+				opts.Analyzers = make(map[string]analyze.Analyzer)
+			}
+			opts.Analyzers["Go"] = analyze.GoAnalyzer{ClosuresInParent: *c.Go.ClosuresInParent}
+		}
+	}
+
+	if m, err := analyze.ParseComplexityMetric(c.ComplexityMetric); err == nil && c.ComplexityMetric != "" {
+		opts.ComplexityMetric = m
+	}
+	if m, err := analyze.ParseChurnMetric(c.ChurnMetric); err == nil && c.ChurnMetric != "" {
+		opts.ChurnMetric = m
+	}
+
+	if c.Coupling != nil {
+		if c.Coupling.MinShared != nil {
+			opts.Coupling.MinShared = *c.Coupling.MinShared
+		}
+		if c.Coupling.MaxFiles != nil {
+			opts.Coupling.MaxFiles = *c.Coupling.MaxFiles
+		}
+	}
+
+	if c.History != nil {
+		opts.History.Since = c.History.Since
+		opts.History.Until = c.History.Until
+		if g, err := analyze.ParseGranularity(c.History.Granularity); err == nil && c.History.Granularity != "" {
+			opts.History.Granularity = g
+		}
+		if d, err := analyze.ParseHalfLife(c.History.HalfLife); err == nil {
+			opts.History.HalfLife = d
+		}
+	}
+
+	if c.Fixes != nil {
+		r := analyze.DefaultFixRules
+		if c.Fixes.Types != nil {
+			r.Types = c.Fixes.Types
+		}
+		if c.Fixes.Patterns != nil {
+			if patterns, err := analyze.CompileFixPatterns(c.Fixes.Patterns); err == nil {
+				r.Patterns = patterns
+			}
+		}
+		opts.FixRules = r
+	}
+
+	if c.Commits != nil {
+		if c.Commits.KeepMerges != nil {
+			opts.Filters.KeepMerges = *c.Commits.KeepMerges
+		}
+		if c.Commits.IgnoreAuthors != nil {
+			if patterns, err := analyze.CompileAuthorPatterns(c.Commits.IgnoreAuthors); err == nil {
+				opts.Filters.IgnoreAuthors = patterns
+			}
+		}
+		if c.Commits.IgnoreRevsFile != "" {
+			opts.Filters.IgnoreRevsFile = c.Commits.IgnoreRevsFile
+		}
+		if c.Commits.MaxFiles != nil {
+			opts.Filters.MaxFiles = *c.Commits.MaxFiles
+		}
+	}
+
+	if s, err := analyze.LookupScorer(c.Scorer); err == nil && c.Scorer != "" {
+		opts.Scorer = s
+	}
+	if c.Weights != nil {
+		w := analyze.DefaultWeights
+		setFloat(&w.Complexity, c.Weights.Complexity)
+		setFloat(&w.Churn, c.Weights.Churn)
+		opts.Weights = w
+	}
+
+	if c.Bands != nil {
+		t := analyze.DefaultThresholds
+		setFloat(&t.Medium, c.Bands.Medium)
+		setFloat(&t.High, c.Bands.High)
+		setFloat(&t.Critical, c.Bands.Critical)
+		opts.Thresholds = t
+	}
+}
+
+func setFloat(dst *float64, v *float64) {
+	if v != nil {
+		*dst = *v
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+func TestAnalyzersLanguages(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    map[string]string // language to analyzer name
+		wantErr string
+	}{
+		{
+			name: "exact name",
+			yaml: "analyzers: {Python: generic}",
+			want: map[string]string{"Python": "generic"},
+		},
+		{
+			name: "case is ignored",
+			yaml: "analyzers: {python: generic, Typescript: generic}",
+			want: map[string]string{"Python": "generic", "TypeScript": "generic"},
+		},
+		{
+			name: "language from extensions",
+			yaml: "extensions: {.kt: Kotlin}\nanalyzers: {kotlin: generic}",
+			want: map[string]string{"Kotlin": "generic"},
+		},
+		{
+			name:    "unknown language",
+			yaml:    "analyzers: {Klingon: generic}",
+			wantErr: `unknown language "Klingon" in analyzers`,
+		},
+		{
+			name:    "unknown analyzer",
+			yaml:    "analyzers: {Go: gofmt}",
+			wantErr: `unknown analyzer "gofmt"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".noisemap.yml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var opts analyze.Options
+			c.Apply(&opts)
+			if len(opts.Analyzers) != len(tt.want) {
+				t.Errorf("Analyzers = %v, want %v", opts.Analyzers, tt.want)
+			}
+			for lang, name := range tt.want {
+				if a, ok := opts.Analyzers[lang]; !ok || a.Name() != name {
+					t.Errorf("Analyzers[%q] = %v, want %s", lang, a, name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+// Coupling lists the pairs of files that most often change together.
+type Coupling struct {
+	Root      string         `json:"root"`
+	MinShared int            `json:"min_shared"`
+	MaxFiles  int            `json:"max_files"`
+	Pairs     []CouplingPair `json:"pairs"`
+}
+
+// CouplingPair is two files that changed together, with their risk bands.
+type CouplingPair struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Shared int     `json:"shared_commits"`
+	Degree float64 `json:"degree"` // 0–1, see analyze.Coupling
+	BandA  string  `json:"band_a"`
+	BandB  string  `json:"band_b"`
+}
+
+// NewCoupling collects the coupled pairs found by a scan, most strongly
+// coupled first, keeping at most limit pairs (all when limit is 0).
+func NewCoupling(root string, scores []analyze.FileScore, opts analyze.CouplingOptions, limit int) *Coupling {
+	if opts.MinShared <= 0 {
+		opts.MinShared = analyze.DefaultCouplingOptions.MinShared
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = analyze.DefaultCouplingOptions.MaxFiles
+	}
+	c := &Coupling{Root: root, MinShared: opts.MinShared, MaxFiles: opts.MaxFiles, Pairs: []CouplingPair{}}
+
+	bands := make(map[string]string, len(scores))
+	for _, s := range scores {
+		bands[filepath.ToSlash(s.File.RelPath)] = s.RiskBand.String()
+	}
+	// Each pair is listed under both of its files; keep it once.
+	for _, s := range scores {
+		path := filepath.ToSlash(s.File.RelPath)
+		for _, p := range s.ChurnResult.Coupled {
+			if path < p.Path {
+				c.Pairs = append(c.Pairs, CouplingPair{
+					A: path, B: p.Path,
+					Shared: p.Shared,
+					Degree: round2(p.Degree),
+					BandA:  bands[path],
+					BandB:  bands[p.Path],
+				})
+			}
+		}
+	}
+	sort.Slice(c.Pairs, func(i, j int) bool {
+		a, b := c.Pairs[i], c.Pairs[j]
+		if a.Degree != b.Degree {
+			return a.Degree > b.Degree
+		}
+		if a.Shared != b.Shared {
+			return a.Shared > b.Shared
+		}
+		if a.A != b.A {
+			return a.A < b.A
+		}
+		return a.B < b.B
+	})
+	if limit > 0 && len(c.Pairs) > limit {
+		c.Pairs = c.Pairs[:limit]
+	}
+	return c
+}
+
+// WriteJSON writes the coupling report as indented JSON.
+func (c *Coupling) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// WriteText writes one line per coupled pair.
+func (c *Coupling) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Files changing together (at least %d shared commits, commits over %d files skipped):\n\n",
+		c.MinShared, c.MaxFiles)
+	if len(c.Pairs) == 0 {
+		fmt.Fprintln(w, "  none")
+		return
+	}
+	for _, p := range c.Pairs {
+		fmt.Fprintf(w, "  %3.0f%%  %4d commits  %s (%s) ↔ %s (%s)\n",
+			p.Degree*100, p.Shared, p.A, p.BandA, p.B, p.BandB)
+	}
+}
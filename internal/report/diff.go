@@ -0,0 +1,177 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Diff is the comparison of a scan against a baseline.
+type Diff struct {
+	Baseline  Summary     `json:"baseline"`
+	Current   Summary     `json:"current"`
+	Added     []FileDelta `json:"added"`
+	Removed   []FileDelta `json:"removed"`
+	Changed   []FileDelta `json:"changed"`
+	Functions []FuncDelta `json:"functions"`
+	// Warnings name differences between the two reports' schema versions
+	// that affect the comparison.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// FileDelta describes a file that appeared, disappeared or moved in risk.
+// Old fields are zero for added files, New fields for removed files.
+type FileDelta struct {
+	Path     string  `json:"path"`
+	OldScore float64 `json:"old_score"`
+	NewScore float64 `json:"new_score"`
+	OldBand  string  `json:"old_band,omitempty"`
+	NewBand  string  `json:"new_band,omitempty"`
+}
+
+// FuncDelta describes a function whose complexity went up. OldComplexity is
+// zero for functions that are new in an existing file.
+type FuncDelta struct {
+	Path          string `json:"path"`
+	Name          string `json:"name"`
+	Line          int    `json:"line"`
+	OldComplexity int    `json:"old_complexity"`
+	NewComplexity int    `json:"new_complexity"`
+}
+
+// Compare reports how current differs from baseline. Files whose risk
+// score moved by less than minDelta points and whose band did not change
+// are not reported as changed; scores are normalized against the riskiest
+// file, so small drifts are expected between any two scans.
+//
+// Before schema version 3 Go function literals were folded into the
+// functions containing them, so functions of Go files are not compared
+// when only one of the reports predates it.
+func Compare(baseline, current *Report, minDelta float64) *Diff {
+	d := &Diff{
+		Baseline:  baseline.Summary,
+		Current:   current.Summary,
+		Added:     []FileDelta{},
+		Removed:   []FileDelta{},
+		Changed:   []FileDelta{},
+		Functions: []FuncDelta{},
+	}
+
+	old := make(map[string]File, len(baseline.Files))
+	for _, f := range baseline.Files {
+		old[f.Path] = f
+	}
+	seen := make(map[string]bool, len(current.Files))
+	goFolded := (baseline.SchemaVersion < 3) != (current.SchemaVersion < 3)
+	goFiles := false
+
+	for _, f := range current.Files {
+		seen[f.Path] = true
+		prev, ok := old[f.Path]
+		if !ok {
+			d.Added = append(d.Added, FileDelta{
+				Path: f.Path, NewScore: f.RiskScore, NewBand: f.RiskBand,
+			})
+			continue
+		}
+
+		if prev.RiskBand != f.RiskBand || math.Abs(f.RiskScore-prev.RiskScore) >= minDelta {
+			d.Changed = append(d.Changed, FileDelta{
+				Path:     f.Path,
+				OldScore: prev.RiskScore,
+				NewScore: f.RiskScore,
+				OldBand:  prev.RiskBand,
+				NewBand:  f.RiskBand,
+			})
+		}
+
+		if goFolded && f.Language == "Go" {
+			goFiles = true
+			continue
+		}
+		prevFuncs := make(map[string]int, len(prev.Functions))
+		for _, fn := range prev.Functions {
+			if fn.Complexity > prevFuncs[fn.Name] {
+				prevFuncs[fn.Name] = fn.Complexity
+			}
+		}
+		for _, fn := range f.Functions {
+			if was := prevFuncs[fn.Name]; fn.Complexity > was {
+				d.Functions = append(d.Functions, FuncDelta{
+					Path:          f.Path,
+					Name:          fn.Name,
+					Line:          fn.Line,
+					OldComplexity: was,
+					NewComplexity: fn.Complexity,
+				})
+			}
+		}
+	}
+
+	for _, f := range baseline.Files {
+		if !seen[f.Path] {
+			d.Removed = append(d.Removed, FileDelta{
+				Path: f.Path, OldScore: f.RiskScore, OldBand: f.RiskBand,
+			})
+		}
+	}
+
+	if goFiles {
+		d.Warnings = append(d.Warnings, fmt.Sprintf(
+			"schema version %d and %d reports count Go function literals differently: "+
+				"Go functions are not compared, and Go complexity totals and risk scores may differ for that reason alone",
+			baseline.SchemaVersion, current.SchemaVersion))
+	}
+	return d
+}
+
+// WriteJSON writes the diff as indented JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteText writes a human-readable summary of the diff.
+func (d *Diff) WriteText(w io.Writer) {
+	for _, warning := range d.Warnings {
+		fmt.Fprintf(w, "Warning: %s.\n\n", warning)
+	}
+	fmt.Fprintf(w, "Files:     %d → %d\n", d.Baseline.Files, d.Current.Files)
+	fmt.Fprintf(w, "Critical:  %d → %d\n", d.Baseline.Critical, d.Current.Critical)
+	fmt.Fprintf(w, "High:      %d → %d\n", d.Baseline.High, d.Current.High)
+	fmt.Fprintf(w, "Medium:    %d → %d\n", d.Baseline.Medium, d.Current.Medium)
+	fmt.Fprintf(w, "Low:       %d → %d\n", d.Baseline.Low, d.Current.Low)
+
+	if len(d.Added) > 0 {
+		fmt.Fprintf(w, "\nNew files (%d):\n", len(d.Added))
+		for _, f := range d.Added {
+			fmt.Fprintf(w, "  + %-50s %5.1f  %s\n", f.Path, f.NewScore, f.NewBand)
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintf(w, "\nRemoved files (%d):\n", len(d.Removed))
+		for _, f := range d.Removed {
+			fmt.Fprintf(w, "  - %-50s %5.1f  %s\n", f.Path, f.OldScore, f.OldBand)
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintf(w, "\nChanged risk (%d):\n", len(d.Changed))
+		for _, f := range d.Changed {
+			band := f.NewBand
+			if f.OldBand != f.NewBand {
+				band = f.OldBand + " → " + f.NewBand
+			}
+			fmt.Fprintf(w, "  ~ %-50s %5.1f → %5.1f  (%+.1f)  %s\n",
+				f.Path, f.OldScore, f.NewScore, f.NewScore-f.OldScore, band)
+		}
+	}
+	if len(d.Functions) > 0 {
+		fmt.Fprintf(w, "\nFunctions with higher complexity (%d):\n", len(d.Functions))
+		for _, fn := range d.Functions {
+			fmt.Fprintf(w, "  ↑ %s:%d %s  %d → %d\n",
+				fn.Path, fn.Line, fn.Name, fn.OldComplexity, fn.NewComplexity)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareGoFunctionsAcrossSchemaVersions(t *testing.T) {
+	baselineFiles := []File{
+		{Path: "serve.go", Language: "Go", Functions: []Function{{Name: "Serve", Complexity: 5}}},
+		{Path: "app.py", Language: "Python", Functions: []Function{{Name: "run", Complexity: 2}}},
+	}
+	currentFiles := []File{
+		{Path: "serve.go", Language: "Go", Functions: []Function{
+			{Name: "Serve", Complexity: 3},
+			{Name: "Serve.func1", Complexity: 3},
+		}},
+		{Path: "app.py", Language: "Python", Functions: []Function{{Name: "run", Complexity: 4}}},
+	}
+
+	tests := []struct {
+		name         string
+		baseline     int
+		wantFuncs    []string
+		wantWarnings int
+	}{
+		{"version 2 baseline", 2, []string{"app.py run"}, 1},
+		{"version 1 baseline", 1, []string{"app.py run"}, 1},
+		{"same version", SchemaVersion, []string{"serve.go Serve.func1", "app.py run"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compare(
+				&Report{SchemaVersion: tt.baseline, Files: baselineFiles},
+				&Report{SchemaVersion: SchemaVersion, Files: currentFiles}, 5)
+			var got []string
+			for _, fn := range d.Functions {
+				got = append(got, fn.Path+" "+fn.Name)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.wantFuncs, ", ") {
+				t.Errorf("Functions = %q, want %q", got, tt.wantFuncs)
+			}
+			if len(d.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %q, want %d", d.Warnings, tt.wantWarnings)
+			}
+			var sb strings.Builder
+			d.WriteText(&sb)
+			if hasWarning := strings.HasPrefix(sb.String(), "Warning: "); hasWarning != (tt.wantWarnings > 0) {
+				t.Errorf("WriteText starts with a warning: %v, want %v", hasWarning, tt.wantWarnings > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+// Gate holds the thresholds that a scan must stay within to pass. Each
+// field documents the value that disables its check; MaxHigh is active at
+// zero, so a zero Gate allows no High or Critical files.
+type Gate struct {
+	// FailOn fails the gate when any file reaches this band. Nil disables
+	// the check.
+	FailOn *analyze.RiskBand
+	// MaxHigh is the number of RiskHigh-or-worse files allowed. Negative
+	// disables the check.
+	MaxHigh int
+	// MaxFuncComplexity is the highest complexity allowed for a single
+	// function. Zero disables the check.
+	MaxFuncComplexity int
+	// MaxFuncCognitive is the highest cognitive complexity allowed for a
+	// single function. Zero disables the check.
+	MaxFuncCognitive int
+}
+
+// Violation is a single reason the gate failed.
+type Violation struct {
+	Rule    string // "fail-on", "max-high", "max-complexity" or "max-cognitive"
+	Path    string // offending file, empty for repo-wide rules
+	Message string
+}
+
+// Evaluate checks scores against the gate and returns every violation, in
+// the order of scores.
+func (g Gate) Evaluate(scores []analyze.FileScore) []Violation {
+	var out []Violation
+
+	high := 0
+	for _, s := range scores {
+		path := filepath.ToSlash(s.File.RelPath)
+
+		if s.RiskBand >= analyze.RiskHigh {
+			high++
+		}
+		if g.FailOn != nil && s.RiskBand >= *g.FailOn {
+			out = append(out, Violation{
+				Rule: "fail-on",
+				Path: path,
+				Message: fmt.Sprintf("risk %.1f is %s (limit: below %s)",
+					s.RiskScore, s.RiskBand, *g.FailOn),
+			})
+		}
+		for _, fn := range s.ComplexityResult.Functions {
+			if g.MaxFuncComplexity > 0 && fn.Complexity > g.MaxFuncComplexity {
+				out = append(out, Violation{
+					Rule: "max-complexity",
+					Path: path,
+					Message: fmt.Sprintf("%s (line %d) has complexity %d (limit: %d)",
+						fn.Name, fn.Line, fn.Complexity, g.MaxFuncComplexity),
+				})
+			}
+			if g.MaxFuncCognitive > 0 && fn.Cognitive > g.MaxFuncCognitive {
+				out = append(out, Violation{
+					Rule: "max-cognitive",
+					Path: path,
+					Message: fmt.Sprintf("%s (line %d) has cognitive complexity %d (limit: %d)",
+						fn.Name, fn.Line, fn.Cognitive, g.MaxFuncCognitive),
+				})
+			}
+		}
+	}
+
+	if g.MaxHigh >= 0 && high > g.MaxHigh {
+		out = append(out, Violation{
+			Rule:    "max-high",
+			Message: fmt.Sprintf("%d files are High or Critical (limit: %d)", high, g.MaxHigh),
+		})
+	}
+
+	return out
+}
+
+// WriteSummary prints a short human-readable gate result.
+func WriteSummary(w io.Writer, scores []analyze.FileScore, violations []Violation) {
+	if len(violations) == 0 {
+		fmt.Fprintf(w, "noisemap: gate passed (%d files checked)\n", len(scores))
+		return
+	}
+
+	fmt.Fprintf(w, "noisemap: gate failed with %d violation(s) across %d files\n",
+		len(violations), len(scores))
+	for _, v := range violations {
+		if v.Path == "" {
+			fmt.Fprintf(w, "  [%s] %s\n", v.Rule, v.Message)
+		} else {
+			fmt.Fprintf(w, "  [%s] %s: %s\n", v.Rule, v.Path, v.Message)
+		}
+	}
+}
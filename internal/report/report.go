@@ -0,0 +1,351 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+// SchemaVersion identifies the layout of the JSON document. It is bumped
+// whenever a field is renamed, removed or changes meaning, so consumers can
+// refuse documents they do not understand.
+//
+// Version 2 replaced each file's monthly_buckets with buckets, whose width
+// is given by window.granularity, and added window. Version 3 lists Go
+// function literals as functions of their own, which changes the
+// complexity and cognitive totals of Go files that contain them: each
+// literal counts once, cognitive complexity no longer adds the nesting of
+// the enclosing function, and literals in package-level variables count
+// too. Version 2 documents still load unchanged.
+const SchemaVersion = 3
+
+// Report is the machine-readable result of a scan.
+type Report struct {
+	SchemaVersion int       `json:"schema_version"`
+	Tool          string    `json:"tool"`
+	ToolVersion   string    `json:"tool_version"`
+	Root          string    `json:"root"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	DurationMS    int64     `json:"duration_ms"`
+	Scorer        string    `json:"scorer"` // how risk_score was computed
+	Window        Window    `json:"window"`
+	Bands         Bands     `json:"bands"`
+	Summary       Summary   `json:"summary"`
+	Files         []File    `json:"files"`
+}
+
+// Window is the part of the git history that churn covers.
+type Window struct {
+	Since       string `json:"since,omitempty"` // a date or git revision, as given
+	Until       string `json:"until,omitempty"`
+	Granularity string `json:"granularity"` // width of each churn bucket
+	HalfLife    string `json:"half_life"`   // of decayed churn, e.g. "90d"
+}
+
+// Bands are the risk scores at which each band starts.
+type Bands struct {
+	Medium   float64 `json:"medium"`
+	High     float64 `json:"high"`
+	Critical float64 `json:"critical"`
+}
+
+// Summary counts files per risk band.
+type Summary struct {
+	Files    int `json:"files"`
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// File is the exported result for a single source file.
+type File struct {
+	Path           string     `json:"path"`
+	Language       string     `json:"language"`
+	RiskScore      float64    `json:"risk_score"`
+	RiskBand       string     `json:"risk_band"`
+	ComplexityNorm float64    `json:"complexity_norm"`
+	ChurnNorm      float64    `json:"churn_norm"`
+	Complexity     int        `json:"complexity"` // cyclomatic
+	Cognitive      int        `json:"cognitive"`
+	Lines          int        `json:"lines"`
+	Functions      []Function `json:"functions"`
+	Churn          Churn      `json:"churn"`
+	// Explanation is absent from documents written before it was added.
+	Explanation *Explanation `json:"explanation,omitempty"`
+	// Fallback says why the generic analyzer measured the file instead of
+	// its own, if it did.
+	Fallback string `json:"fallback,omitempty"`
+}
+
+// Explanation accounts for a file's risk score.
+type Explanation struct {
+	// Floor is the score the file must drop below to leave its band; 0 in
+	// the Low band.
+	Floor   float64  `json:"floor"`
+	Signals []Signal `json:"signals"` // complexity, then churn
+	// TopFunctions are the functions driving complexity, most complex
+	// first.
+	TopFunctions []Function `json:"top_functions"`
+}
+
+// Signal is one input of a file's risk score.
+type Signal struct {
+	Signal string  `json:"signal"` // "complexity" or "churn"
+	Metric string  `json:"metric"`
+	Value  float64 `json:"value"`
+	Norm   float64 `json:"norm"`   // 0–100
+	Weight float64 `json:"weight"` // 0–1
+	// Points is the part of risk_score due to this signal.
+	Points float64 `json:"points"`
+	// Max is the highest value in the scan, found in MaxPath.
+	Max     float64 `json:"max"`
+	MaxPath string  `json:"max_path,omitempty"`
+	// Rank is the number of other files whose value is at most Value.
+	Rank int `json:"rank"`
+	// Below is the value the signal must fall below, the other signal
+	// unchanged, for the file to drop a band; 0 means it must reach 0.
+	// It is absent in the Low band and where this signal alone cannot.
+	Below *float64 `json:"below,omitempty"`
+}
+
+// Function is the exported complexity of a single function.
+type Function struct {
+	Name string `json:"name"`
+	// ID qualifies Name so that it is unique across the repository, e.g.
+	// with the Go import path. It is omitted where a language has none.
+	ID         string `json:"id,omitempty"`
+	Complexity int    `json:"complexity"` // cyclomatic
+	Cognitive  int    `json:"cognitive"`
+	Line       int    `json:"line"`
+}
+
+// Churn is the exported git history of a single file.
+type Churn struct {
+	IsGitRepo    bool  `json:"is_git_repo"`
+	TotalCommits int   `json:"total_commits"`
+	FixCommits   int   `json:"fix_commits"`
+	Buckets      []int `json:"buckets"` // 12 buckets of Window.Granularity, oldest first
+	// MonthlyBuckets is only read from schema version 1 documents, which
+	// Load converts to Buckets.
+	MonthlyBuckets []int `json:"monthly_buckets,omitempty"`
+	LinesAdded     int   `json:"lines_added"`
+	LinesDeleted   int   `json:"lines_deleted"`
+	// Decayed is the commit count with each commit weighted by its age.
+	Decayed float64 `json:"decayed"`
+	// RelativeChurn is lines added plus deleted per line of the file.
+	RelativeChurn float64 `json:"relative_churn"`
+	// Authors are ordered by share of lines; the first is the owner.
+	Authors   []Author `json:"authors"`
+	Owner     string   `json:"owner,omitempty"`
+	BusFactor int      `json:"bus_factor"`
+}
+
+// Author is one author's share of a file's history, after .mailmap.
+type Author struct {
+	Name        string  `json:"name"`
+	Email       string  `json:"email"`
+	Commits     int     `json:"commits"`
+	Lines       int     `json:"lines"`
+	CommitShare float64 `json:"commit_share"` // 0–1
+	LineShare   float64 `json:"line_share"`   // 0–1
+}
+
+// newFunction exports fn.
+func newFunction(fn analyze.FuncComplexity) Function {
+	return Function{
+		Name:       fn.Name,
+		ID:         qualifiedID(fn),
+		Complexity: fn.Complexity,
+		Cognitive:  fn.Cognitive,
+		Line:       fn.Line,
+	}
+}
+
+// newExplanation exports e.
+func newExplanation(e analyze.Explanation) *Explanation {
+	signal := func(name string, s analyze.Signal) Signal {
+		out := Signal{
+			Signal:  name,
+			Metric:  s.Metric,
+			Value:   round2(s.Value),
+			Norm:    round2(s.Norm),
+			Weight:  round2(s.Weight),
+			Points:  round2(s.Points),
+			Max:     round2(s.Max),
+			MaxPath: filepath.ToSlash(s.MaxPath),
+			Rank:    s.Rank,
+		}
+		if s.Lowerable {
+			below := round2(s.Below)
+			out.Below = &below
+		}
+		return out
+	}
+	out := &Explanation{
+		Floor:        e.Floor,
+		Signals:      []Signal{signal("complexity", e.Complexity), signal("churn", e.Churn)},
+		TopFunctions: make([]Function, 0, len(e.TopFunctions)),
+	}
+	for _, fn := range e.TopFunctions {
+		out.TopFunctions = append(out.TopFunctions, newFunction(fn))
+	}
+	return out
+}
+
+// qualifiedID returns fn's ID if it differs from its bare name.
+func qualifiedID(fn analyze.FuncComplexity) string {
+	if id := fn.ID(); id != fn.Name {
+		return id
+	}
+	return ""
+}
+
+// New builds a Report from files scored with opts. Files keep the order of
+// scores.
+func New(root, toolVersion string, scores []analyze.FileScore, opts analyze.Options, dur time.Duration) *Report {
+	halfLife := opts.History.HalfLife
+	if halfLife <= 0 {
+		halfLife = analyze.DefaultHalfLife
+	}
+	scorer := analyze.DefaultScorer
+	if opts.Scorer != nil {
+		scorer = opts.Scorer.Name()
+	}
+	bands := opts.Thresholds
+	if bands == (analyze.Thresholds{}) {
+		bands = analyze.DefaultThresholds
+	}
+	r := &Report{
+		SchemaVersion: SchemaVersion,
+		Tool:          "noisemap",
+		ToolVersion:   toolVersion,
+		Root:          root,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		DurationMS:    dur.Milliseconds(),
+		Scorer:        scorer,
+		Window: Window{
+			Since:       opts.History.Since,
+			Until:       opts.History.Until,
+			Granularity: opts.History.Granularity.String(),
+			HalfLife:    analyze.FormatHalfLife(halfLife),
+		},
+		Bands: Bands{Medium: bands.Medium, High: bands.High, Critical: bands.Critical},
+		Files: make([]File, 0, len(scores)),
+	}
+
+	for _, s := range scores {
+		r.Summary.Files++
+		switch s.RiskBand {
+		case analyze.RiskCritical:
+			r.Summary.Critical++
+		case analyze.RiskHigh:
+			r.Summary.High++
+		case analyze.RiskMedium:
+			r.Summary.Medium++
+		default:
+			r.Summary.Low++
+		}
+
+		funcs := make([]Function, 0, len(s.ComplexityResult.Functions))
+		for _, fn := range s.ComplexityResult.Functions {
+			funcs = append(funcs, newFunction(fn))
+		}
+
+		authors := make([]Author, 0, len(s.ChurnResult.Authors))
+		for _, a := range s.ChurnResult.Authors {
+			authors = append(authors, Author{
+				Name:        a.Name,
+				Email:       a.Email,
+				Commits:     a.Commits,
+				Lines:       a.Lines,
+				CommitShare: round2(a.CommitShare),
+				LineShare:   round2(a.LineShare),
+			})
+		}
+		owner, _ := s.ChurnResult.Owner()
+
+		buckets := s.ChurnResult.Buckets
+		if buckets == nil {
+			buckets = []int{}
+		}
+
+		r.Files = append(r.Files, File{
+			Path:           filepath.ToSlash(s.File.RelPath),
+			Language:       s.File.Language,
+			RiskScore:      round2(s.RiskScore),
+			RiskBand:       s.RiskBand.String(),
+			ComplexityNorm: round2(s.ComplexityNorm),
+			ChurnNorm:      round2(s.ChurnNorm),
+			Complexity:     s.ComplexityResult.Total,
+			Cognitive:      s.ComplexityResult.Cognitive,
+			Lines:          s.ComplexityResult.Lines,
+			Functions:      funcs,
+			Fallback:       s.ComplexityResult.Fallback,
+			Churn: Churn{
+				IsGitRepo:     s.ChurnResult.IsGitRepo,
+				TotalCommits:  s.ChurnResult.TotalCommits,
+				FixCommits:    s.ChurnResult.FixCommits,
+				Buckets:       buckets,
+				LinesAdded:    s.ChurnResult.LinesAdded,
+				LinesDeleted:  s.ChurnResult.LinesDeleted,
+				Decayed:       round2(s.ChurnResult.Decayed),
+				RelativeChurn: round2(s.ChurnResult.RelativeChurn),
+				Authors:       authors,
+				Owner:         owner.Name,
+				BusFactor:     s.ChurnResult.BusFactor,
+			},
+			Explanation: newExplanation(s.Explanation),
+		})
+	}
+
+	return r
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// round2 rounds to two decimals so that exported scores diff cleanly.
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Load reads a report previously written by WriteJSON.
+func Load(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: not a noisemap report: %w", path, err)
+	}
+	if r.Tool != "noisemap" {
+		return nil, fmt.Errorf("%s: not a noisemap report", path)
+	}
+	if r.SchemaVersion < 1 || r.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("%s: unsupported schema version %d (this build reads up to %d)",
+			path, r.SchemaVersion, SchemaVersion)
+	}
+
+	// Version 1 always had twelve monthly buckets over all of history.
+	if r.SchemaVersion == 1 {
+		r.Window = Window{Granularity: analyze.GranularityMonth.String()}
+		for i := range r.Files {
+			c := &r.Files[i].Churn
+			c.Buckets, c.MonthlyBuckets = c.MonthlyBuckets, nil
+		}
+	}
+	return &r, nil
+}
@@ -18,7 +18,7 @@ func renderDetail(m *Model) string {
 	}
 
 	s := m.scores[m.cursor]
-	color := RiskColor(s.RiskScore)
+	color := BandColor(s.RiskBand)
 
 	// ── Header ──────────────────────────────────────────────────────────────
 	riskLabel := lipgloss.NewStyle().
@@ -37,34 +37,107 @@ func renderDetail(m *Model) string {
 	}
 
 	sb.WriteString(stat("Language:", s.File.Language, ColorAccent))
+	scorer := analyze.DefaultScorer
+	if m.opts.Scorer != nil {
+		scorer = m.opts.Scorer.Name()
+	}
 	sb.WriteString(stat("Risk Score:",
-		fmt.Sprintf("%.1f / 100", s.RiskScore), color))
-	sb.WriteString(stat("Complexity:",
-		fmt.Sprintf("%d  (norm: %.0f%%)", s.ComplexityResult.Total, s.ComplexityNorm),
-		colorByNorm(s.ComplexityNorm)))
-	sb.WriteString(stat("Git Churn:",
-		fmt.Sprintf("%d commits  (norm: %.0f%%)", s.ChurnResult.TotalCommits, s.ChurnNorm),
-		colorByNorm(s.ChurnNorm)))
+		fmt.Sprintf("%.1f / 100  (%s)", s.RiskScore, scorer), color))
+	// The normalized score belongs to whichever metric feeds the risk.
+	cyclomatic := fmt.Sprintf("%d", s.ComplexityResult.Total)
+	cognitive := fmt.Sprintf("%d", s.ComplexityResult.Cognitive)
+	norm := fmt.Sprintf("  (norm: %.0f%%)", s.ComplexityNorm)
+	if m.opts.ComplexityMetric == analyze.MetricCognitive {
+		cognitive += norm
+	} else {
+		cyclomatic += norm
+	}
+	sb.WriteString(stat("Complexity:", cyclomatic, colorByNorm(s.ComplexityNorm)))
+	sb.WriteString(stat("Cognitive:", cognitive, colorByNorm(s.ComplexityNorm)))
+	// Likewise for churn, where the line-based metrics share a row.
+	ch := s.ChurnResult
+	commits := fmt.Sprintf("%d commits", ch.TotalCommits)
+	fixes := fmt.Sprintf("%d of %d commits", ch.FixCommits, ch.TotalCommits)
+	lines := fmt.Sprintf("+%d / -%d  (net %+d)", ch.LinesAdded, ch.LinesDeleted, ch.NetLines())
+	relative := fmt.Sprintf("%.2f× file size", ch.RelativeChurn)
+	halfLife := m.opts.History.HalfLife
+	if halfLife <= 0 {
+		halfLife = analyze.DefaultHalfLife
+	}
+	decayed := fmt.Sprintf("%.1f commits  (half-life %s)", ch.Decayed, analyze.FormatHalfLife(halfLife))
+	norm = fmt.Sprintf("  (norm: %.0f%%)", s.ChurnNorm)
+	switch m.opts.ChurnMetric {
+	case analyze.ChurnCommits:
+		commits += norm
+	case analyze.ChurnRelative:
+		relative += norm
+	case analyze.ChurnFixes:
+		fixes += norm
+	case analyze.ChurnDecayed:
+		decayed = fmt.Sprintf("%.1f commits", ch.Decayed) + norm
+	default:
+		lines += norm
+	}
+	sb.WriteString(stat("Git Churn:", commits, colorByNorm(s.ChurnNorm)))
+	sb.WriteString(stat("Fix Commits:", fixes, colorByNorm(s.ChurnNorm)))
+	sb.WriteString(stat("Lines Churned:", lines, colorByNorm(s.ChurnNorm)))
+	sb.WriteString(stat("Relative:", relative, colorByNorm(s.ChurnNorm)))
+	sb.WriteString(stat("Decayed:", decayed, colorByNorm(s.ChurnNorm)))
+
+	// A single owner is worth flagging, loudly so on a risky file.
+	if len(ch.Authors) > 0 {
+		busColor := ColorLow
+		if ch.BusFactor == 1 {
+			busColor = ColorMedium
+			if s.RiskBand >= analyze.RiskHigh {
+				busColor = ColorCritical
+			}
+		}
+		sb.WriteString(stat("Authors:",
+			fmt.Sprintf("%d  (bus factor %d)", len(ch.Authors), ch.BusFactor), busColor))
+		limit := min(3, len(ch.Authors))
+		for rank, a := range ch.Authors[:limit] {
+			sb.WriteString(fmt.Sprintf("   %d. %-20.20s %s\n", rank+1, a.Name,
+				HelpStyle.Render(fmt.Sprintf("%3.0f%% of lines, %3.0f%% of commits",
+					a.LineShare*100, a.CommitShare*100))))
+		}
+	}
+
+	if len(ch.Coupled) > 0 {
+		sb.WriteString(stat("Changes With:", fmt.Sprintf("%d files", len(ch.Coupled)), ColorAccent))
+		limit := min(3, len(ch.Coupled))
+		for _, p := range ch.Coupled[:limit] {
+			sb.WriteString(fmt.Sprintf("   %3.0f%%  %s %s\n", p.Degree*100,
+				truncateLeft(p.Path, m.rightWidth-28),
+				HelpStyle.Render(fmt.Sprintf("(%d commits)", p.Shared))))
+		}
+	}
 
 	if !s.ChurnResult.IsGitRepo {
 		sb.WriteString(HelpStyle.Render("  (not a git repo — churn is 0)\n"))
 	}
+	if fb := s.ComplexityResult.Fallback; fb != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(ColorMedium).
+			Render("  ⚠ "+fb+"; measured with the generic analyzer") + "\n")
+	}
 
 	// ── Churn Sparkline ──────────────────────────────────────────────────────
 	sb.WriteString("\n")
-	sb.WriteString(StatLabelStyle.Render("12-month churn:"))
+	sb.WriteString(StatLabelStyle.Render(fmt.Sprintf("%d-%s churn:",
+		analyze.ChurnBuckets, m.opts.History.Granularity)))
 	if s.ChurnResult.IsGitRepo {
-		sb.WriteString(sparkline(s.ChurnResult.MonthlyBuckets))
+		sb.WriteString(sparkline(s.ChurnResult.Buckets))
 		sb.WriteString(HelpStyle.Render("  (older → newer)"))
 	} else {
 		sb.WriteString(HelpStyle.Render("N/A"))
 	}
 	sb.WriteString("\n\n")
 
-	// ── Top Functions (Go only) ──────────────────────────────────────────────
-	if s.File.Language == "Go" && len(s.ComplexityResult.Functions) > 0 {
+	// ── Top Functions (languages with a function-level analyzer) ─────────────
+	if len(s.ComplexityResult.Functions) > 0 {
 		sb.WriteString(lipgloss.NewStyle().Foreground(ColorAccent).Bold(true).
-			Render("Top Functions by Complexity") + "\n")
+			Render("Top Functions by Complexity") +
+			HelpStyle.Render("  (cyclomatic / cognitive)") + "\n")
 		sb.WriteString(strings.Repeat("─", m.rightWidth-4) + "\n")
 
 		limit := 5
@@ -78,7 +151,7 @@ func renderDetail(m *Model) string {
 				rank+1,
 				fn.Name,
 				lipgloss.NewStyle().Foreground(fnColor).Bold(true).
-					Render(fmt.Sprintf("complexity: %d  (line %d)", fn.Complexity, fn.Line)),
+					Render(fmt.Sprintf("%3d / %-3d (line %d)", fn.Complexity, fn.Cognitive, fn.Line)),
 			))
 		}
 	}
@@ -86,18 +159,20 @@ func renderDetail(m *Model) string {
 	return sb.String()
 }
 
-// colorByNorm returns a risk color based on a normalized 0–100 value.
-func colorByNorm(norm float64) lipgloss.Color {
-	switch {
-	case norm >= 80:
-		return ColorCritical
-	case norm >= 60:
-		return ColorHigh
-	case norm >= 30:
-		return ColorMedium
-	default:
-		return ColorLow
+// truncateLeft shortens s to at most n runes by dropping its start, which
+// keeps the file name of a long path visible.
+func truncateLeft(s string, n int) string {
+	r := []rune(s)
+	if n < 2 || len(r) <= n {
+		return s
 	}
+	return "…" + string(r[len(r)-n+1:])
+}
+
+// colorByNorm returns a risk color based on a normalized 0–100 value,
+// using the default band cutoffs.
+func colorByNorm(norm float64) lipgloss.Color {
+	return BandColor(analyze.DefaultThresholds.Band(norm))
 }
 
 // renderSummaryStats renders aggregate project stats above the detail pane.
@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+// renderExplanation renders the breakdown of the selected file's risk
+// score in place of the detail pane.
+func renderExplanation(m *Model) string {
+	var sb strings.Builder
+
+	if len(m.scores) == 0 || m.cursor >= len(m.scores) {
+		sb.WriteString(HelpStyle.Render("Select a file to inspect."))
+		return sb.String()
+	}
+
+	s := m.scores[m.cursor]
+	e := s.Explanation
+	color := BandColor(s.RiskBand)
+	heading := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true)
+
+	// ── Header ──────────────────────────────────────────────────────────────
+	sb.WriteString(TitleStyle.Render("🧮 Why This Score") + "\n")
+	sb.WriteString(strings.Repeat("─", m.rightWidth-4) + "\n")
+	sb.WriteString(SubtitleStyle.Render(s.File.RelPath) + "\n")
+	sb.WriteString(lipgloss.NewStyle().Foreground(color).Bold(true).
+		Render(fmt.Sprintf("%s  %s  %.1f / 100", s.RiskBand.Emoji(), s.RiskBand, s.RiskScore)) +
+		HelpStyle.Render(fmt.Sprintf("  (%s scorer)", e.Scorer)) + "\n\n")
+
+	// ── Contributions ────────────────────────────────────────────────────────
+	sb.WriteString(StatLabelStyle.Render("") +
+		HelpStyle.Render(fmt.Sprintf("%10s %6s %7s %7s", "Value", "Norm", "Weight", "Points")) + "\n")
+	for _, sig := range []struct {
+		label string
+		s     analyze.Signal
+		norm  float64
+	}{{"Complexity", e.Complexity, s.ComplexityNorm}, {"Churn", e.Churn, s.ChurnNorm}} {
+		sb.WriteString(StatLabelStyle.Render(sig.label) +
+			lipgloss.NewStyle().Foreground(colorByNorm(sig.norm)).Bold(true).Render(
+				fmt.Sprintf("%10s %5.0f%% %6.0f%% %7.1f",
+					formatValue(sig.s.Value), sig.s.Norm, sig.s.Weight*100, sig.s.Points)) + "\n")
+		sb.WriteString("  " + HelpStyle.Render(sig.s.Metric+", "+reference(m, e.Scorer, sig.s, s.File.RelPath)) + "\n")
+	}
+	sb.WriteString(StatLabelStyle.Render("Risk Score") +
+		lipgloss.NewStyle().Foreground(color).Bold(true).
+			Render(fmt.Sprintf("%33.1f", s.RiskScore)) + "\n")
+	if e.Scorer == "hotspot" {
+		sb.WriteString(HelpStyle.Render("  complexity × churn against the top hotspot;\n  points are split by weight") + "\n")
+	}
+
+	t := e.Thresholds
+	sb.WriteString("\n" + StatLabelStyle.Render("Bands:") + HelpStyle.Render(fmt.Sprintf(
+		"Medium from %g · High from %g · Critical from %g", t.Medium, t.High, t.Critical)) + "\n\n")
+
+	// ── What would lower it ──────────────────────────────────────────────────
+	if e.Floor <= 0 {
+		sb.WriteString(heading.Render("Already in the lowest band.") + "\n")
+	} else {
+		lower := s.RiskBand - 1
+		sb.WriteString(heading.Render(fmt.Sprintf("To drop to %s (below %g):", lower, e.Floor)) + "\n")
+		lowered := false
+		for _, sig := range []analyze.Signal{e.Complexity, e.Churn} {
+			switch {
+			case !sig.Lowerable:
+				sb.WriteString(HelpStyle.Render(fmt.Sprintf("  • %s alone cannot get it there", sig.Metric)) + "\n")
+				continue
+			case sig.Below == 0:
+				sb.WriteString(fmt.Sprintf("  • %s down to 0", sig.Metric))
+			default:
+				sb.WriteString(fmt.Sprintf("  • %s below %s", sig.Metric, formatValue(sig.Below)))
+			}
+			sb.WriteString(HelpStyle.Render(fmt.Sprintf("  (now %s)", formatValue(sig.Value))) + "\n")
+			lowered = true
+		}
+		if !lowered {
+			sb.WriteString(HelpStyle.Render("  both signals have to come down") + "\n")
+		}
+	}
+
+	// ── Functions driving complexity ─────────────────────────────────────────
+	if len(e.TopFunctions) > 0 {
+		sb.WriteString("\n" + heading.Render("Driving Complexity") +
+			HelpStyle.Render(fmt.Sprintf("  (%s)", e.Complexity.Metric)) + "\n")
+		for rank, fn := range e.TopFunctions {
+			v := fn.Complexity
+			if m.opts.ComplexityMetric == analyze.MetricCognitive {
+				v = fn.Cognitive
+			}
+			share := ""
+			if e.Complexity.Value > 0 {
+				share = fmt.Sprintf("%3.0f%% of file", float64(v)/e.Complexity.Value*100)
+			}
+			sb.WriteString(fmt.Sprintf(" %d. %-30s %4d  %s\n", rank+1,
+				truncateLeft(fn.Name, 30), v, HelpStyle.Render(share)))
+		}
+	}
+
+	return sb.String()
+}
+
+// reference describes what sig was normalized against.
+func reference(m *Model, scorer string, sig analyze.Signal, path string) string {
+	if scorer == "percentile" {
+		return fmt.Sprintf("at least as high as %d of %d other files", sig.Rank, sig.Files-1)
+	}
+	if sig.MaxPath == "" {
+		return "no file has any"
+	}
+	where := "this file"
+	if sig.MaxPath != path {
+		where = truncateLeft(sig.MaxPath, m.rightWidth-len(sig.Metric)-24)
+	}
+	prefix := "max"
+	if scorer == "log" {
+		prefix = "log scale, max"
+	}
+	return fmt.Sprintf("%s %s (%s)", prefix, formatValue(sig.Max), where)
+}
+
+// formatValue formats a signal value: whole numbers as such, others with
+// two decimals.
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
@@ -23,7 +23,7 @@ func renderFileList(m *Model) string {
 	}
 
 	// Determine visible window
-	visibleHeight := m.height - 8
+	visibleHeight := m.paneHeight() - 3
 	if visibleHeight < 1 {
 		visibleHeight = 1
 	}
@@ -39,7 +39,7 @@ func renderFileList(m *Model) string {
 
 	for i := start; i < end; i++ {
 		s := m.scores[i]
-		color := RiskColor(s.RiskScore)
+		color := BandColor(s.RiskBand)
 		badge := BadgeStyle(color).Render("██")
 
 		name := filepath.Base(s.File.RelPath)
@@ -30,7 +30,7 @@ func renderHeatmap(m *Model) string {
 	}
 
 	for i, s := range m.scores {
-		color := RiskColor(s.RiskScore)
+		color := BandColor(s.RiskBand)
 		block := lipgloss.NewStyle().Foreground(color).Render("██")
 
 		if i == m.cursor {
@@ -51,7 +51,7 @@ func renderHeatmap(m *Model) string {
 	// Show selected file info
 	if m.cursor < len(m.scores) {
 		s := m.scores[m.cursor]
-		color := RiskColor(s.RiskScore)
+		color := BandColor(s.RiskBand)
 		sb.WriteString(lipgloss.NewStyle().Foreground(color).Bold(true).Render(
 			fmt.Sprintf("▶ %s  — Risk: %.0f  Complexity: %d  Churn: %d commits",
 				s.File.RelPath,
@@ -73,7 +73,7 @@ func colorLegend() string {
 		lipgloss.NewStyle().Foreground(ColorCritical).Render("██") + " Critical"
 }
 
-// sparkline renders an ASCII bar chart from monthly buckets.
+// sparkline renders an ASCII bar chart from churn buckets.
 func sparkline(buckets []int) string {
 	if len(buckets) == 0 {
 		return HelpStyle.Render("no git history")
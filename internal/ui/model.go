@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -30,11 +31,15 @@ const (
 // Model is the root Bubble Tea model.
 type Model struct {
 	root       string
+	opts       analyze.Options
 	scores     []analyze.FileScore
 	cursor     int
 	sortBy     analyze.SortBy
 	viewMode   ViewMode
 	activePane ActivePane
+	// explain shows the selected file's risk explanation in place of its
+	// details.
+	explain bool
 
 	width      int
 	height     int
@@ -46,6 +51,12 @@ type Model struct {
 	scanErr      error
 	scanStart    time.Time
 	scanDuration time.Duration
+	// scanID identifies the in-flight scan so results from a cancelled
+	// scan are ignored; cancelScan stops it.
+	scanID     int
+	cancelScan context.CancelFunc
+	firstScan  tea.Cmd
+	progress   analyze.Progress
 
 	spinner     int
 	spinnerTick int
@@ -53,11 +64,19 @@ type Model struct {
 
 // scanDoneMsg carries the results of the background scan.
 type scanDoneMsg struct {
+	id     int
 	scores []analyze.FileScore
 	err    error
 	dur    time.Duration
 }
 
+// progressMsg carries a progress snapshot from the background scan.
+type progressMsg struct {
+	id int
+	p  analyze.Progress
+	ch <-chan analyze.Progress
+}
+
 type tickMsg struct{}
 
 func tick() tea.Cmd {
@@ -66,44 +85,71 @@ func tick() tea.Cmd {
 	})
 }
 
-// New creates a new Model for the given root directory.
-func New(root string) Model {
+// New creates a new Model for the given root directory. The first scan
+// starts immediately so that it can be cancelled before Init runs.
+func New(root string, opts analyze.Options) Model {
 	abs, _ := filepath.Abs(root)
-	return Model{
-		root:      abs,
-		scanning:  true,
-		scanStart: time.Now(),
-		sortBy:    analyze.SortByRisk,
+	m := Model{
+		root:   abs,
+		opts:   opts,
+		sortBy: analyze.SortByRisk,
 	}
+	m.firstScan = m.startScan()
+	return m
 }
 
 // Init starts the background scan.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(tick(), m.runScan())
+	return tea.Batch(tick(), m.firstScan)
 }
 
-// runScan kicks off analysis in a goroutine.
-func (m Model) runScan() tea.Cmd {
-	return func() tea.Msg {
-		start := time.Now()
-
-		files, err := analyze.Walk(m.root)
-		if err != nil {
-			return scanDoneMsg{err: err, dur: time.Since(start)}
+// startScan cancels any in-flight scan and starts a new one.
+func (m *Model) startScan() tea.Cmd {
+	if m.cancelScan != nil {
+		m.cancelScan()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelScan = cancel
+	m.scanID++
+	m.scanning = true
+	m.scanStart = time.Now()
+	m.progress = analyze.Progress{}
+
+	// The channel holds only the latest snapshot: the scan replaces an
+	// unread one rather than blocking on a slow render.
+	ch := make(chan analyze.Progress, 1)
+	opts := m.opts
+	opts.Progress = func(p analyze.Progress) {
+		select {
+		case <-ch:
+		default:
 		}
+		ch <- p
+	}
+	return tea.Batch(
+		runScan(ctx, m.scanID, m.root, opts, ch),
+		waitProgress(m.scanID, ch),
+	)
+}
 
-		complexities := make([]analyze.ComplexityResult, len(files))
-		churns := make([]analyze.ChurnResult, len(files))
+// runScan runs the analysis in the background and closes ch when done.
+func runScan(ctx context.Context, id int, root string, opts analyze.Options, ch chan analyze.Progress) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		scores, err := analyze.Scan(ctx, root, opts)
+		close(ch)
+		return scanDoneMsg{id: id, scores: scores, err: err, dur: time.Since(start)}
+	}
+}
 
-		for i, f := range files {
-			complexities[i] = analyze.AnalyzeComplexity(f)
-			churns[i] = analyze.AnalyzeChurn(f, m.root)
+// waitProgress waits for the next progress snapshot of scan id.
+func waitProgress(id int, ch <-chan analyze.Progress) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-ch
+		if !ok {
+			return nil
 		}
-
-		scores := analyze.Score(files, complexities, churns)
-		analyze.SortScores(scores, analyze.SortByRisk)
-
-		return scanDoneMsg{scores: scores, dur: time.Since(start)}
+		return progressMsg{id: id, p: p, ch: ch}
 	}
 }
 
@@ -121,7 +167,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner = (m.scanner()) % 8
 		return m, tick()
 
+	case progressMsg:
+		if msg.id != m.scanID {
+			return m, nil
+		}
+		m.progress = msg.p
+		return m, waitProgress(msg.id, msg.ch)
+
 	case scanDoneMsg:
+		if msg.id != m.scanID {
+			return m, nil // superseded by a newer scan
+		}
+		m.cancelScan()
+		m.cancelScan = nil
 		m.scanning = false
 		m.scanDone = true
 		m.scanErr = msg.err
@@ -145,9 +203,12 @@ func (m *Model) recalcPanes() {
 }
 
 // handleKey processes key events.
-func (m Model) handleKey(msg tea.KeyMsg) tea.Cmd {
+func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 	switch msg.String() {
 	case "q", "ctrl+c":
+		if m.cancelScan != nil {
+			m.cancelScan()
+		}
 		return tea.Quit
 
 	case "j", "down":
@@ -182,15 +243,16 @@ func (m Model) handleKey(msg tea.KeyMsg) tea.Cmd {
 			m.viewMode = ViewList
 		}
 
+	case "e":
+		m.explain = !m.explain
+
 	case "s":
 		m.sortBy = (m.sortBy + 1) % 4
 		analyze.SortScores(m.scores, m.sortBy)
 		m.cursor = 0
 
 	case "r":
-		m.scanning = true
-		m.scanStart = time.Now()
-		return m.runScan()
+		return m.startScan()
 	}
 
 	return nil
@@ -226,8 +288,81 @@ func (m Model) renderScanning() string {
 	frame := spinnerFrames[m.spinnerTick%len(spinnerFrames)]
 	msg := lipgloss.NewStyle().Foreground(ColorAccent).Bold(true).
 		Render(fmt.Sprintf("\n  %s  Scanning %s...\n", frame, m.root))
-	hint := HelpStyle.Render("  Analyzing complexity and git history")
-	return msg + "\n" + hint
+
+	p := m.progress
+	if p.FilesFound == 0 {
+		hint := HelpStyle.Render("  Discovering files")
+		return msg + "\n" + hint
+	}
+
+	const barWidth = 40
+	filled := int(p.Fraction() * barWidth)
+	bar := lipgloss.NewStyle().Foreground(ColorAccent).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(ColorDim).Render(strings.Repeat("░", barWidth-filled))
+
+	elapsed := time.Since(m.scanStart)
+	eta := "estimating..."
+	if d := p.ETA(elapsed); d > 0 {
+		eta = d.Round(time.Second).String()
+	}
+
+	history := "reading git history"
+	if p.HistoryLoaded {
+		history = fmt.Sprintf("%d/%d", p.ChurnDone, p.FilesFound)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(msg + "\n")
+	sb.WriteString(fmt.Sprintf("  %s %s\n\n", bar,
+		NormalItemStyle.Render(fmt.Sprintf("%3.0f%%", p.Fraction()*100))))
+	sb.WriteString("  " + StatLabelStyle.Render("Files found:") + NormalItemStyle.Render(fmt.Sprintf("%d", p.FilesFound)) + "\n")
+	sb.WriteString("  " + StatLabelStyle.Render("Complexity:") + NormalItemStyle.Render(fmt.Sprintf("%d/%d", p.ComplexityDone, p.FilesFound)) + "\n")
+	sb.WriteString("  " + StatLabelStyle.Render("Churn:") + NormalItemStyle.Render(history) + "\n")
+	sb.WriteString("  " + StatLabelStyle.Render("Elapsed / ETA:") + NormalItemStyle.Render(
+		fmt.Sprintf("%s / %s", elapsed.Round(time.Second), eta)) + "\n")
+	if p.Current != "" {
+		sb.WriteString("\n  " + HelpStyle.Render(p.Current) + "\n")
+	}
+	sb.WriteString("\n  " + HelpStyle.Render("r restart  q quit"))
+	return sb.String()
+}
+
+// filterLine renders the history window and the active include/exclude
+// globs as a second header line, or "" when none are set.
+func (m Model) filterLine() string {
+	h := m.opts.History
+	if len(m.opts.Include) == 0 && len(m.opts.Exclude) == 0 && h.Since == "" && h.Until == "" {
+		return ""
+	}
+	var parts []string
+	if h.Since != "" {
+		parts = append(parts, KeyStyle.Render("since ")+SubtitleStyle.Render(h.Since))
+	}
+	if h.Until != "" {
+		parts = append(parts, KeyStyle.Render("until ")+SubtitleStyle.Render(h.Until))
+	}
+	if len(m.opts.Include) > 0 {
+		parts = append(parts, KeyStyle.Render("include ")+
+			SubtitleStyle.Render(strings.Join(m.opts.Include, "  ")))
+	}
+	if len(m.opts.Exclude) > 0 {
+		parts = append(parts, KeyStyle.Render("exclude ")+
+			SubtitleStyle.Render(strings.Join(m.opts.Exclude, "  ")))
+	}
+	return "\n" + strings.Join(parts, "    ")
+}
+
+// headerLines is the height of the header bar.
+func (m Model) headerLines() int {
+	if m.filterLine() == "" {
+		return 1
+	}
+	return 2
+}
+
+// paneHeight is the height of the main content panes below the header.
+func (m Model) paneHeight() int {
+	return m.height - 4 - m.headerLines()
 }
 
 func (m Model) renderListView() string {
@@ -238,7 +373,7 @@ func (m Model) renderListView() string {
 	dur := SubtitleStyle.Render(fmt.Sprintf("  scanned in %s", m.scanDuration.Round(time.Millisecond)))
 	header := HeaderBarStyle.Width(m.width).Render(
 		fmt.Sprintf("󱁢 noisemap  %s  %s  %s", m.root, total, dur) +
-			strings.Repeat(" ", 4) + stats,
+			strings.Repeat(" ", 4) + stats + m.filterLine(),
 	)
 
 	// Left pane - file list
@@ -248,7 +383,7 @@ func (m Model) renderListView() string {
 		leftStyle = ActivePaneStyle
 	}
 	leftContent := renderFileList(&m)
-	leftPane := leftStyle.Width(m.leftWidth).Height(m.height - 5).Render(leftContent)
+	leftPane := leftStyle.Width(m.leftWidth).Height(m.paneHeight()).Render(leftContent)
 
 	// Right pane - detail
 	rightActive := m.activePane == PaneDetail
@@ -257,12 +392,18 @@ func (m Model) renderListView() string {
 		rightStyle = ActivePaneStyle
 	}
 	rightContent := renderDetail(&m)
-	rightPane := rightStyle.Width(m.rightWidth).Height(m.height - 5).Render(rightContent)
+	explainHint := " explain  "
+	if m.explain {
+		rightContent = renderExplanation(&m)
+		explainHint = " details  "
+	}
+	rightPane := rightStyle.Width(m.rightWidth).Height(m.paneHeight()).Render(rightContent)
 
 	// Status bar
 	statusBar := StatusBarStyle.Width(m.width).Render(
 		KeyStyle.Render("j/k") + HelpStyle.Render(" navigate  ") +
 			KeyStyle.Render("Tab") + HelpStyle.Render(" switch pane  ") +
+			KeyStyle.Render("e") + HelpStyle.Render(explainHint) +
 			KeyStyle.Render("v") + HelpStyle.Render(" heatmap  ") +
 			KeyStyle.Render("s") + HelpStyle.Render(" sort  ") +
 			KeyStyle.Render("r") + HelpStyle.Render(" rescan  ") +
@@ -276,9 +417,9 @@ func (m Model) renderListView() string {
 
 func (m Model) renderHeatmapView() string {
 	header := HeaderBarStyle.Width(m.width).Render(
-		fmt.Sprintf("󱁢 noisemap  %s  %d files", m.root, len(m.scores)),
+		fmt.Sprintf("󱁢 noisemap  %s  %d files", m.root, len(m.scores)) + m.filterLine(),
 	)
-	content := PaneStyle.Width(m.width - 4).Height(m.height - 5).Render(renderHeatmap(&m))
+	content := PaneStyle.Width(m.width - 4).Height(m.paneHeight()).Render(renderHeatmap(&m))
 	statusBar := StatusBarStyle.Width(m.width).Render(
 		KeyStyle.Render("j/k") + HelpStyle.Render(" navigate  ") +
 			KeyStyle.Render("v") + HelpStyle.Render(" list view  ") +
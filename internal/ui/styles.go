@@ -1,6 +1,9 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
 
 // Color palette — dark terminal theme
 var (
@@ -22,14 +25,14 @@ var (
 	ColorDim    = lipgloss.Color("#414868")
 )
 
-// RiskColor returns the lipgloss color for a given risk score (0–100).
-func RiskColor(score float64) lipgloss.Color {
-	switch {
-	case score >= 80:
+// BandColor returns the lipgloss color for a risk band.
+func BandColor(band analyze.RiskBand) lipgloss.Color {
+	switch band {
+	case analyze.RiskCritical:
 		return ColorCritical
-	case score >= 60:
+	case analyze.RiskHigh:
 		return ColorHigh
-	case score >= 30:
+	case analyze.RiskMedium:
 		return ColorMedium
 	default:
 		return ColorLow
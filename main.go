@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 
@@ -21,7 +23,7 @@ const banner = `
 func main() {
 	args := os.Args[1:]
 
-	// Handle flags
+	// Handle subcommands and flags that exit immediately
 	if len(args) > 0 {
 		switch args[0] {
 		case "--version", "-v":
@@ -30,23 +32,33 @@ func main() {
 		case "--help", "-h":
 			printHelp()
 			return
+		case "scan":
+			exitOnError(runScan(args[1:]))
+			return
+		case "check":
+			os.Exit(runCheck(args[1:]))
+		case "diff":
+			exitOnError(runDiff(args[1:]))
+			return
+		case "coupling":
+			exitOnError(runCoupling(args[1:]))
+			return
 		}
 	}
 
-	// Determine the root directory to scan
-	root := "."
-	if len(args) > 0 {
-		root = args[0]
-	}
-
-	// Verify it exists
-	if _, err := os.Stat(root); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: directory %q does not exist\n", root)
-		os.Exit(1)
-	}
+	fs := flag.NewFlagSet("noisemap", flag.ContinueOnError)
+	fs.Usage = printHelp
+	var sf scanFlags
+	sf.register(fs)
+	positional, err := parseArgs(fs, args)
+	exitOnError(err)
+	root, err := resolveRoot(positional)
+	exitOnError(err)
+	opts, err := sf.options(root)
+	exitOnError(err)
 
 	// Launch TUI
-	m := ui.New(root)
+	m := ui.New(root, opts)
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running noisemap: %v\n", err)
@@ -54,16 +66,51 @@ func main() {
 	}
 }
 
+// exitOnError reports err from a subcommand and exits non-zero.
+func exitOnError(err error) {
+	if err == nil {
+		return
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(1)
+}
+
 func printHelp() {
 	fmt.Printf("%s\n\n", banner)
 	fmt.Println("Codebase complexity heatmap for your terminal.")
 	fmt.Println()
 	fmt.Println("USAGE:")
-	fmt.Println("  noisemap [directory]")
+	fmt.Println("  noisemap [flags] [directory]")
+	fmt.Println("  noisemap scan [flags] [directory]")
+	fmt.Println("  noisemap check [flags] [directory]")
+	fmt.Println("  noisemap diff [flags] <baseline.json> [directory | report.json]")
+	fmt.Println("  noisemap coupling [flags] [directory]")
 	fmt.Println()
 	fmt.Println("ARGUMENTS:")
 	fmt.Println("  directory    Path to scan (default: current directory)")
 	fmt.Println()
+	fmt.Println("COMMANDS:")
+	fmt.Println("  scan         Scan without the TUI and write a report")
+	fmt.Println("                 --format json     Output format (default: json)")
+	fmt.Println("                 -o, --output F    Write to file F instead of stdout")
+	fmt.Println("  check        CI quality gate; prints violating files")
+	fmt.Println("                 --fail-on BAND        Fail if any file reaches BAND (default: critical, or none)")
+	fmt.Println("                 --max-high N          Fail if more than N files are High or Critical")
+	fmt.Println("                 --max-complexity N    Fail if any function's complexity exceeds N")
+	fmt.Println("                 --max-cognitive N     Fail if any function's cognitive complexity exceeds N")
+	fmt.Println("               Exit codes: 0 passed, 1 gate failed, 2 usage or scan error")
+	fmt.Println("  diff         Compare a saved baseline against a new scan or report")
+	fmt.Println("                 --format text|json    Output format (default: text)")
+	fmt.Println("                 --min-delta P         Ignore score moves under P points (default: 1)")
+	fmt.Println("  coupling     List files that change in the same commits")
+	fmt.Println("                 --format text|json    Output format (default: text)")
+	fmt.Println("                 --limit N             Show at most N pairs, 0 for all (default: 20)")
+	fmt.Println("                 --min-shared N        Pair files sharing at least N commits (default: 3)")
+	fmt.Println("                 --max-files N         Skip commits touching more than N files (default: 50)")
+	fmt.Println()
 	fmt.Println("KEYBINDINGS:")
 	fmt.Println("  j / ↓        Move down")
 	fmt.Println("  k / ↑        Move up")
@@ -71,11 +118,47 @@ func printHelp() {
 	fmt.Println("  G            Jump to bottom")
 	fmt.Println("  Tab          Switch pane (list ↔ detail)")
 	fmt.Println("  v            Toggle heatmap / list view")
+	fmt.Println("  e            Explain the selected file's risk score")
 	fmt.Println("  s            Cycle sort: risk → complexity → churn → name")
 	fmt.Println("  r            Re-scan the directory")
 	fmt.Println("  q / Ctrl+C   Quit")
 	fmt.Println()
 	fmt.Println("FLAGS:")
+	fmt.Println("  -j, --jobs N    Analyze N files in parallel (default: number of CPUs)")
+	fmt.Println("  -q, --quiet     Do not print scan progress to stderr (CLI commands)")
+	fmt.Println("  --no-cache      Analyze every file from scratch")
+	fmt.Println("  --cache-dir D   Keep the analysis cache in D (default: user cache dir)")
+	fmt.Println("  --config F      Read settings from F instead of the nearest .noisemap.yml")
+	fmt.Println("  --no-config     Ignore .noisemap.yml files")
+	fmt.Println("  --no-gitignore  Also scan files ignored by .gitignore and .git/info/exclude")
+	fmt.Println("  --include GLOB  Only scan files whose path matches GLOB (repeatable)")
+	fmt.Println("  --exclude GLOB  Skip files whose path matches GLOB (repeatable)")
+	fmt.Println("  --complexity-metric M")
+	fmt.Println("                  Complexity fed into the risk score: cyclomatic or cognitive")
+	fmt.Println("  --churn-metric M")
+	fmt.Println("                  Churn fed into the risk score: commits, lines (added + deleted),")
+	fmt.Println("                  added, deleted, net, relative (lines churned per line of file),")
+	fmt.Println("                  decayed (commits weighted by age) or fixes (bug-fix commits)")
+	fmt.Println("  --since REV, --until REV")
+	fmt.Println("                  Only count churn in this window; dates (YYYY-MM-DD) or git revisions")
+	fmt.Println("  --granularity G Width of the churn activity buckets: week, month or quarter")
+	fmt.Println("  --half-life H   Age at which a commit counts half in decayed churn (default: 90d)")
+	fmt.Println("  --keep-merges   Count merge commits in churn")
+	fmt.Println("  --ignore-author RE")
+	fmt.Println("                  Skip commits whose author \"Name <email>\" matches RE (repeatable;")
+	fmt.Println("                  default: dependabot, renovate and [bot] accounts)")
+	fmt.Println("  --ignore-revs-file F")
+	fmt.Println("                  Skip the commits listed in F (default: .git-blame-ignore-revs)")
+	fmt.Println("  --max-commit-files N")
+	fmt.Println("                  Skip commits touching more than N files, e.g. mass reformats")
+	fmt.Println("  --scorer S      How the inputs combine into the risk score: linear (share of the")
+	fmt.Println("                  highest value), percentile (rank), log (log-scaled) or hotspot")
+	fmt.Println("                  (complexity × churn)")
+	fmt.Println("  --complexity-weight W, --churn-weight W")
+	fmt.Println("                  Relative weights of the risk score inputs (default: 0.6, 0.4)")
+	fmt.Println("  --bands M,H,C   Scores where Medium, High and Critical start (default: 30,60,80)")
 	fmt.Println("  -h, --help      Show this help")
 	fmt.Println("  -v, --version   Show version")
+	fmt.Println()
+	fmt.Println("Scan flags are accepted by the TUI and by scan, check, diff and coupling.")
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+)
+
+// progressPrinter renders scan progress on stderr. On a terminal it redraws
+// a single line; otherwise it prints a line every few seconds so CI logs
+// stay readable.
+type progressPrinter struct {
+	out      *os.File
+	tty      bool
+	start    time.Time
+	last     time.Time
+	latest   analyze.Progress
+	interval time.Duration
+}
+
+func newProgressPrinter(out *os.File) *progressPrinter {
+	p := &progressPrinter{out: out, start: time.Now(), interval: 2 * time.Second}
+	if info, err := out.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		p.tty = true
+		p.interval = 100 * time.Millisecond
+	}
+	return p
+}
+
+// update records a progress snapshot and prints it if enough time passed
+// since the previous line. It is used as analyze.Options.Progress.
+func (p *progressPrinter) update(pr analyze.Progress) {
+	p.latest = pr
+	if time.Since(p.last) < p.interval {
+		return
+	}
+	p.last = time.Now()
+	p.print()
+}
+
+// finish prints the final state and ends the progress line.
+func (p *progressPrinter) finish() {
+	p.print()
+	if p.tty {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *progressPrinter) print() {
+	pr := p.latest
+	elapsed := time.Since(p.start)
+
+	const width = 24
+	filled := int(pr.Fraction() * width)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+
+	line := fmt.Sprintf("%s %3.0f%%  %d files  complexity %d/%d  churn %d/%d",
+		bar, pr.Fraction()*100, pr.FilesFound,
+		pr.ComplexityDone, pr.FilesFound, pr.ChurnDone, pr.FilesFound)
+	if eta := pr.ETA(elapsed); eta > 0 {
+		line += fmt.Sprintf("  ETA %s", eta.Round(time.Second))
+	}
+
+	if p.tty {
+		if pr.Current != "" {
+			line += "  " + pr.Current
+		}
+		// \033[K clears whatever a longer previous line left behind.
+		fmt.Fprintf(p.out, "\r%s\033[K", line)
+		return
+	}
+	fmt.Fprintln(p.out, line)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/meetsoni15/noisemap/internal/analyze"
+	"github.com/meetsoni15/noisemap/internal/report"
+)
+
+// runScan implements `noisemap scan`: a headless scan that writes a report.
+func runScan(args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	var sf scanFlags
+	sf.register(fs)
+	format := fs.String("format", "json", "output format (json)")
+	var output string
+	fs.StringVar(&output, "output", "", "write the report to `file` instead of stdout")
+	fs.StringVar(&output, "o", "", "shorthand for --output")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if *format != "json" {
+		return fmt.Errorf("unsupported format %q (supported: json)", *format)
+	}
+
+	root, err := resolveRoot(positional)
+	if err != nil {
+		return err
+	}
+
+	rep, err := scanReport(root, &sf)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		return rep.WriteJSON(os.Stdout)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	// Close may report a failed write (e.g. on NFS) that would otherwise
+	// leave a truncated report behind a zero exit status.
+	err = rep.WriteJSON(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// scanReport scans root and wraps the scores in a report.
+func scanReport(root string, sf *scanFlags) (*report.Report, error) {
+	opts, err := sf.options(root)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	scores, err := scanWithOptions(root, opts, sf.quiet)
+	if err != nil {
+		return nil, err
+	}
+	return report.New(root, version, scores, opts, time.Since(start)), nil
+}
+
+// scanScores runs the analysis pipeline on root with the options from sf.
+func scanScores(root string, sf *scanFlags) ([]analyze.FileScore, error) {
+	opts, err := sf.options(root)
+	if err != nil {
+		return nil, err
+	}
+	return scanWithOptions(root, opts, sf.quiet)
+}
+
+// scanWithOptions runs the analysis pipeline on root, printing progress
+// and, once done, files the generic analyzer had to measure to stderr
+// unless quiet is set. An interrupt signal cancels the scan.
+func scanWithOptions(root string, opts analyze.Options, quiet bool) ([]analyze.FileScore, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var progress *progressPrinter
+	if !quiet {
+		progress = newProgressPrinter(os.Stderr)
+		opts.Progress = progress.update
+	}
+
+	scores, err := analyze.Scan(ctx, root, opts)
+	if progress != nil {
+		progress.finish()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", root, err)
+	}
+	if !quiet {
+		for _, s := range scores {
+			if s.ComplexityResult.Fallback != "" {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s; used the generic analyzer\n",
+					s.File.RelPath, s.ComplexityResult.Fallback)
+			}
+		}
+	}
+	return scores, nil
+}
+
+// parseArgs parses flags that may appear before or after positional
+// arguments and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// resolveRoot returns the absolute directory to scan from the positional
+// arguments, defaulting to the current directory.
+func resolveRoot(positional []string) (string, error) {
+	root := "."
+	switch len(positional) {
+	case 0:
+	case 1:
+		root = positional[0]
+	default:
+		return "", fmt.Errorf("expected at most one directory, got %d arguments", len(positional))
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return "", fmt.Errorf("directory %q does not exist", root)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%q is not a directory", root)
+	}
+	return filepath.Abs(root)
+}